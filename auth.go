@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeysEnv is the environment variable holding comma-separated API keys.
+const apiKeysEnv = "DECODE_WAYS_API_KEYS"
+
+// apiKeys is the set of accepted API keys, by SHA-256 so that looking a key up
+// takes no time that depends on how much of it matches an accepted one, with
+// the quota of every key: nil for the default quota of the server.
+type apiKeys map[[sha256.Size]byte]*quota
+
+// loadAPIKeys reads the accepted API keys from a file, one per line (empty
+// lines and lines starting with '#' are skipped), and from the environment
+// variable DECODE_WAYS_API_KEYS, separated by commas.
+//
+// A key in the file may be followed by its own quota, overriding the
+// default one of the server (see quota):
+//
+//	team-a-key bytes=100G cpu=10h
+//	team-b-key bytes=1G
+//
+// Parameters:
+//   - filename: Path of the key file, empty for none
+//
+// Returns:
+//   - apiKeys: The keys, nil if there are none and authentication is disabled
+//   - error: An error if the file can not be read or a quota is invalid
+func loadAPIKeys(filename string) (apiKeys, error) {
+	var keys []string
+	if filename != "" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			keys = append(keys, sc.Text())
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+	inFile := len(keys)
+	keys = append(keys, strings.Split(os.Getenv(apiKeysEnv), ",")...)
+
+	var k apiKeys
+	for i, line := range keys {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0][0] == '#' {
+			continue
+		}
+		var q *quota
+		if len(fields) > 1 {
+			var err error
+			if q, err = parseQuota(fields[1:]); err != nil && i < inFile {
+				return nil, fmt.Errorf("%s:%d: %w", filename, i+1, err)
+			} else if err != nil {
+				return nil, fmt.Errorf("$%s: %w", apiKeysEnv, err)
+			}
+		}
+		if k == nil {
+			k = apiKeys{}
+		}
+		k[sha256.Sum256([]byte(fields[0]))] = q
+	}
+	return k, nil
+}
+
+// valid tells whether a key is accepted. Every key is if there are none.
+func (k apiKeys) valid(key string) bool {
+	if k == nil {
+		return true
+	}
+	_, ok := k[sha256.Sum256([]byte(key))]
+	return ok
+}
+
+// bearerKey extracts the API key of a request, given either as
+// "Authorization: Bearer <key>" or as "X-API-Key: <key>".
+func bearerKey(authorization, apiKey string) string {
+	if key, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return key
+	}
+	return apiKey
+}
+
+// authenticate rejects HTTP requests without a valid API key with 401.
+func (sv *server) authenticate(h http.Handler) http.Handler {
+	if sv.keys == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !sv.keys.valid(bearerKey(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// authenticateStream rejects gRPC calls without a valid API key in their
+// "authorization" or "x-api-key" metadata with UNAUTHENTICATED.
+func (sv *server) authenticateStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+	if !sv.keys.valid(grpcKey(ss.Context())) {
+		return status.Error(codes.Unauthenticated, "missing or invalid API key")
+	}
+	return h(srv, ss)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// batchResult is the outcome of counting one file of a batch.
+type batchResult struct {
+	count   countValue
+	in      inputInfo     // Only with a results database or a --format other than plain
+	elapsed time.Duration // Time taken by reading and counting the file
+	err     error
+	done    chan struct{} // Closed when the result is ready
+}
+
+// countFile reads a file and counts its decodings.
+//
+// Parameters:
+//   - c: The counter
+//   - filename: Path to the input file
+//   - in: If not nil, set to the hash, length and algorithm of the input
+//
+// Returns:
+//   - string: The count in decimal
+//   - error: An error if the file can not be read or is invalid
+func countFile(c *counter, filename string, in *inputInfo) (string, error) {
+	p, err := loadFile(c, filename, in)
+	if err != nil {
+		return "", err
+	}
+	return c.countInput(p)
+}
+
+// loadFile reads a file to be counted.
+//
+// Parameters:
+//   - c: The counter
+//   - filename: Path to the input file
+//   - in: If not nil, set to the hash, length and algorithm of the input
+//
+// Returns:
+//   - []byte: The content of the file
+//   - error: An error if the file can not be read or counting it would
+//     exceed the memory budget
+func loadFile(c *counter, filename string, in *inputInfo) ([]byte, error) {
+	start := time.Now()
+	p, err := readInput(filename, c.maxLen)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		*in = newInputInfo(c, p)
+	}
+	timings.record("read", start)
+	timings.addDigits(len(p))
+	slog.Info("open", "file", filename, "bytes", len(p))
+	if err := checkMemory(int64(len(p))); err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	return p, nil
+}
+
+// runBatch counts several files with up to workers files in flight.
+//
+// Every file gets a line "<filename>: <count>" on stdout, or an error line on
+// stderr. The lines are printed in the order of the arguments as soon as all
+// previous files are done, so the output does not depend on which worker
+// finishes first. A file that fails does not stop the batch: its error is
+// reported in its place and the other files are still counted. With a
+// results database, every count is also recorded
+// there, in the same order. With dedup, the files are hashed first and each
+// distinct content is counted once: the other files with the same content
+// reuse its count, and are reported on stderr. With --format, every file gets a record in the
+// format instead of the line (see resultWriter), the failed ones too.
+//
+// Parameters:
+//   - c: The counter
+//   - files: Paths of the input files
+//   - workers: Maximum number of files counted concurrently
+//   - db: The results database of --sqlite, nil for none
+//   - rw: The writer of the records
+//   - dedup: Whether files with the same content are counted once (--dedup)
+//
+// Returns:
+//   - int: The process exit code: 0 if all files were counted, exitPartial
+//     if only some were, else that of the first file that failed (see
+//     exitCode)
+func runBatch(c *counter, files []string, workers int, db *resultsDB, rw *resultWriter, dedup bool) int {
+	results := make([]batchResult, len(files))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+	var dups []int
+	if dedup {
+		dups = findDuplicates(files, workers)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if dups != nil && dups[i] >= 0 {
+					// The first file is queued before, so it does not wait on this one
+					j := dups[i]
+					<-results[j].done
+					results[i].count, results[i].err, results[i].in = results[j].count, results[j].err, results[j].in
+					results[i].in.dup = files[j]
+					close(results[i].done)
+					continue
+				}
+				var in *inputInfo
+				if db != nil || rw.provenance || rw.report != nil {
+					in = &results[i].in
+				}
+				start := time.Now()
+				p, err := loadFile(c, files[i], in)
+				if err == nil {
+					results[i].count, err = rw.count(c, p)
+				}
+				results[i].err = err
+				results[i].elapsed = time.Since(start)
+				close(results[i].done)
+			}
+		}()
+	}
+	go func() {
+		for i := range files {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	code, failed := 0, 0
+	for i, name := range files {
+		<-results[i].done
+		if dup := results[i].in.dup; dup != "" {
+			fmt.Fprintf(os.Stderr, "Note: %s has the same content as %s, its count is reused\n", name, dup)
+		}
+		if err := rw.write(name, results[i].count, results[i].err, results[i].in, false, results[i].elapsed); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+		if err := results[i].err; err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding %s: %v\n", name, trError(err))))
+			if code == 0 {
+				code = exitCode(err)
+			}
+			failed++
+			continue
+		}
+		if db != nil {
+			if err := db.record(name, results[i].in.sum, c.options, results[i].count.decimal()); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+				if code == 0 {
+					code = exitIO
+				}
+			}
+		}
+	}
+	wg.Wait()
+	if failed > 0 && failed < len(files) {
+		code = exitPartial
+	}
+	if err := rw.f.Flush(); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return exitIO
+	}
+	return code
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// batchItem is the result of an input of a batch request.
+type batchItem struct {
+	Index  int    `json:"index"` // Position of the input in the request, from 0
+	Count  string `json:"count,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Cached bool   `json:"cached,omitempty"` // Whether the count came from the cache
+}
+
+// countItem counts an input of a batch within the deadline of the request.
+func (sv *server) countItem(ctx context.Context, i int, s string) batchItem {
+	item := batchItem{Index: i}
+	x, hit, err := sv.countWithin(ctx, []byte(s))
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		item.Error = fmt.Sprintf("count not finished within %v", sv.timeout)
+	case err != nil:
+		item.Error, _, _ = strings.Cut(err.Error(), "\n") // One line, without the snippet
+	default:
+		item.Count, item.Cached = x, hit
+	}
+	return item
+}
+
+// isNDJSON tells whether a Content-Type is that of newline-delimited JSON.
+func isNDJSON(contentType string) bool {
+	t, _, _ := mime.ParseMediaType(contentType)
+	return t == "application/x-ndjson" || t == "application/jsonl"
+}
+
+// handleBatch implements POST /v1/count/batch: many small inputs in one
+// request, sparing clients the overhead of a request each.
+//
+// The body is a JSON array of digit strings, answered with a JSON array of a
+// batchItem per input, in the same order:
+//
+//	["226", "12a"] -> [{"index":0,"count":"3"},{"index":1,"error":"..."}]
+//
+// With Content-Type application/x-ndjson it is a stream of JSON strings, one
+// per line, answered with a stream of batchItem lines, each written as soon
+// as its input is counted. An input that can not be counted gets an error in
+// its item, the others are counted anyway. The whole body is limited by
+// --max-input, the number of inputs by --max-batch and the whole request by
+// --request-timeout; a malformed body is answered with 400, or in a stream
+// with a last item carrying the error.
+func (sv *server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body := r.Body
+	if sv.maxInput > 0 {
+		body = http.MaxBytesReader(w, body, sv.maxInput)
+	}
+	ctx, cancel := sv.withTimeout(r.Context())
+	defer cancel()
+	start := time.Now()
+	if isNDJSON(r.Header.Get("Content-Type")) {
+		n := sv.streamBatch(ctx, w, body)
+		slog.Info("batch", "items", n, "stream", true, "duration", time.Since(start))
+		return
+	}
+
+	var inputs []string
+	if err := json.NewDecoder(body).Decode(&inputs); err != nil {
+		writeError(w, batchErrorStatus(err), batchErrorMessage(err))
+		return
+	}
+	if sv.maxBatch > 0 && len(inputs) > sv.maxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("more than %d inputs", sv.maxBatch))
+		return
+	}
+	items := make([]batchItem, len(inputs))
+	for i, s := range inputs {
+		items[i] = sv.countItem(ctx, i, s)
+	}
+	slog.Info("batch", "items", len(items), "stream", false, "duration", time.Since(start))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// streamBatch answers an NDJSON batch request, and returns the number of
+// inputs read.
+func (sv *server) streamBatch(ctx context.Context, w http.ResponseWriter, body io.Reader) int {
+	dec := json.NewDecoder(body)
+	enc := json.NewEncoder(w)
+	rc := http.NewResponseController(w)
+	for i := 0; ; i++ {
+		var s string
+		err := dec.Decode(&s)
+		if errors.Is(err, io.EOF) {
+			return i
+		}
+		if err == nil && sv.maxBatch > 0 && i >= sv.maxBatch {
+			err = &limitError{fmt.Sprintf("more than %d inputs", sv.maxBatch)}
+		}
+		if err != nil {
+			if i == 0 {
+				writeError(w, batchErrorStatus(err), batchErrorMessage(err))
+			} else {
+				enc.Encode(batchItem{Index: i, Error: batchErrorMessage(err)})
+			}
+			return i
+		}
+		if i == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+		if err := enc.Encode(sv.countItem(ctx, i, s)); err != nil {
+			return i + 1 // The client is gone
+		}
+		rc.Flush()
+	}
+}
+
+// batchErrorStatus returns the HTTP status of a malformed or too large batch
+// body.
+func batchErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	var le *limitError
+	if errors.As(err, &tooLarge) || errors.As(err, &le) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// batchErrorMessage returns the message of a malformed or too large batch
+// body.
+func batchErrorMessage(err error) string {
+	var tooLarge *http.MaxBytesError
+	var le *limitError
+	if errors.As(err, &tooLarge) {
+		return fmt.Sprintf("input larger than %d bytes", tooLarge.Limit)
+	} else if errors.As(err, &le) {
+		return le.msg
+	}
+	return fmt.Sprintf("Error reading request: %v", err)
+}
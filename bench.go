@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"task1/decodeways"
+)
+
+// benchProfiles lists the synthetic input profiles. Every profile is a set of
+// tokens that are concatenated at random; no token starts with '0', so any
+// concatenation, and any prefix of it, is a valid input.
+var benchProfiles = map[string][]string{
+	"dense":  {"1", "2", "1", "2", "6"},                 // Long clusters, huge answers
+	"sparse": {"3", "4", "5", "6", "7", "8", "9", "12"}, // Rare short clusters
+	"zeros":  {"10", "20", "1", "7"},                    // Frequent pairs forced by '0'
+}
+
+// parseSize parses a byte size such as "512", "64K", "100M" or "1G".
+// The suffixes are binary: K = 2^10, M = 2^20, G = 2^30.
+//
+// Parameters:
+//   - s: The size string
+//
+// Returns:
+//   - int64: The size in bytes
+//   - error: An error if s is not a positive size
+func parseSize(s string) (int64, error) {
+	digits, shift := s, 0
+	if s != "" {
+		if i := strings.IndexByte("KMG", s[len(s)-1]); i >= 0 {
+			digits, shift = s[:len(s)-1], 10*(i+1)
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n <= 0 || n > 1<<(62-shift) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n << shift, nil
+}
+
+// benchInput generates a synthetic input of the given size and profile.
+//
+// The same seed always gives the same input; bench uses a fixed one, so every
+// run measures the same input.
+//
+// Parameters:
+//   - size: Length of the input in bytes
+//   - tokens: The tokens of the profile
+//   - seed: The seed of the random generator
+//
+// Returns:
+//   - []byte: The generated digit string
+func benchInput(size int64, tokens []string, seed int64) []byte {
+	rnd := rand.New(rand.NewSource(seed))
+	p := make([]byte, 0, size+2)
+	for int64(len(p)) < size {
+		p = append(p, tokens[rnd.Intn(len(tokens))]...)
+	}
+	return p[:size]
+}
+
+// runBench implements the "bench" subcommand.
+//
+// Usage: decode-ways bench [--sizes 1M,100M] [--profiles dense,sparse,zeros] [--threads n]
+//
+// For every size and profile a synthetic input is generated in memory and
+// counted, and the time, throughput and memory allocated by the count are
+// reported. Generation and the decimal conversion of the answer are not timed.
+//
+// The results can be saved as a baseline (--save) and a later run compared
+// with it (--baseline): the run fails if the throughput of an input dropped
+// by more than --fail-over, so performance can be tracked automatically.
+//
+// Returns:
+//   - int: The process exit code
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	sizes := fs.String("sizes", "1M,10M", "comma-separated input `sizes` (K, M and G suffixes)")
+	profiles := fs.String("profiles", "dense,sparse,zeros", "comma-separated input `profiles`")
+	threads := fs.Int("threads", runtime.NumCPU(), "number of `workers` scanning in parallel")
+	warmup := fs.Uint64("warmup", 0, "precompute the Fibonacci numbers up to index `n` before measuring")
+	save := fs.String("save", "", "save the results as a baseline JSON `file`")
+	baselineFile := fs.String("baseline", "", "compare the throughput with a baseline JSON `file` of --save")
+	format := addFormatFlag(fs)
+	failOver := fs.String("fail-over", "10%", "fail if the throughput of an input dropped by more than `percent` against --baseline")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways bench [--sizes 1M,100M,1G] [--profiles dense,sparse,zeros]")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways bench --sizes 1M,100M --profiles sparse")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+
+	var ns []int64
+	for _, s := range strings.Split(*sizes, ",") {
+		n, err := parseSize(s)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return 1
+		}
+		ns = append(ns, n)
+	}
+	names := strings.Split(*profiles, ",")
+	for _, name := range names {
+		if _, ok := benchProfiles[name]; !ok {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: unknown profile %q\n", name)))
+			return 1
+		}
+	}
+
+	tolerance, err := parsePercent(*failOver)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --fail-over: %v\n", trError(err))))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	var baseline []benchResult
+	if *baselineFile != "" {
+		if baseline, err = loadBaseline(*baselineFile); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+	}
+
+	decodeways.Precompute(*warmup)
+	showProgress = false // The bar would interleave with the table
+
+	var results []benchResult
+	for _, n := range ns {
+		for _, name := range names {
+			p := benchInput(n, benchProfiles[name], 1)
+			runtime.GC()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+			x, err := countParallel(p, &decodeways.StandardScheme, *threads)
+			elapsed := time.Since(start)
+			runtime.ReadMemStats(&after)
+			if err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+				return 1
+			}
+			mb := float64(n) / (1 << 20)
+			r := benchResult{Size: n, Profile: name, Seconds: elapsed.Seconds(), MBPerSec: mb / elapsed.Seconds(),
+				AllocMB: float64(after.TotalAlloc-before.TotalAlloc) / (1 << 20), AnswerLen: x.BitLen()}
+			results = append(results, r)
+			if err := f.Format(r); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+				return exitIO
+			}
+		}
+	}
+	if code := formatAll(f); code != 0 {
+		return code
+	}
+
+	if *save != "" {
+		if err := saveBaseline(*save, results); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+	}
+	if baseline != nil {
+		// Below the table, or on stderr not to mix with the records of a format
+		var w io.Writer = os.Stderr
+		if *format == "plain" {
+			w = os.Stdout
+			fmt.Println()
+		}
+		if n := compareBaseline(w, results, baseline, tolerance); n > 0 {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: throughput dropped by more than %s on %d of %d inputs\n", *failOver, n, len(results))))
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// benchResult is the measurement of one input of bench, as stored in a
+// baseline file (--save, --baseline).
+type benchResult struct {
+	Size      int64   `json:"size"`
+	Profile   string  `json:"profile"`
+	Seconds   float64 `json:"seconds"`
+	MBPerSec  float64 `json:"mb_per_s"`
+	AllocMB   float64 `json:"allocated_mb"`
+	AnswerLen int     `json:"answer_bits"`
+}
+
+// plainHeader implements plainTable.
+func (r benchResult) plainHeader() string {
+	return "size\tprofile\ttime\tMB/s\tallocated MB\tanswer bits\t\n"
+}
+
+// plain implements plainRecord.
+func (r benchResult) plain() string {
+	elapsed := time.Duration(r.Seconds * float64(time.Second)).Round(time.Microsecond)
+	return fmt.Sprintf("%d\t%s\t%v\t%.1f\t%.1f\t%d\t\n", r.Size, r.Profile, elapsed, r.MBPerSec, r.AllocMB, r.AnswerLen)
+}
+
+// saveBaseline writes bench results to a baseline file.
+//
+// Parameters:
+//   - path: Path of the file
+//   - results: The results
+//
+// Returns:
+//   - error: An error if the file can not be written
+func saveBaseline(path string, results []benchResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, 0x0A), 0o644)
+}
+
+// loadBaseline reads a baseline file written by saveBaseline.
+//
+// Parameters:
+//   - path: Path of the file
+//
+// Returns:
+//   - []benchResult: The results of the baseline
+//   - error: An error if the file can not be read or parsed
+func loadBaseline(path string) ([]benchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []benchResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("baseline '%s': %w", path, err)
+	}
+	return results, nil
+}
+
+// parsePercent parses a tolerance of --fail-over, e.g. "10%" or "2.5".
+func parsePercent(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	return v, nil
+}
+
+// compareBaseline compares the throughput of bench results with a baseline
+// and writes one line per input: the change against the baseline, marked
+// as a regression if the throughput dropped by more than the tolerance.
+// Inputs missing from the baseline are listed, but are no regression.
+//
+// Parameters:
+//   - w: The writer
+//   - results: The current results
+//   - baseline: The results of the baseline
+//   - tolerance: The largest drop in throughput accepted, in percent
+//
+// Returns:
+//   - int: The number of regressions
+func compareBaseline(w io.Writer, results, baseline []benchResult, tolerance float64) int {
+	type key struct {
+		size    int64
+		profile string
+	}
+	base := map[key]benchResult{}
+	for _, r := range baseline {
+		base[key{r.Size, r.Profile}] = r
+	}
+	regressions := 0
+	for _, r := range results {
+		b, ok := base[key{r.Size, r.Profile}]
+		if !ok || b.MBPerSec <= 0 {
+			fmt.Fprintf(w, "%d %s: %.1f MB/s, not in the baseline\n", r.Size, r.Profile, r.MBPerSec)
+			continue
+		}
+		change := (r.MBPerSec - b.MBPerSec) / b.MBPerSec * 100
+		verdict := "ok"
+		if -change > tolerance {
+			verdict = "REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(w, "%d %s: %.1f MB/s vs %.1f MB/s (%+.1f%%) %s\n", r.Size, r.Profile, r.MBPerSec, b.MBPerSec, change, verdict)
+	}
+	return regressions
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math/big"
+
+	"task1/decodeways"
+)
+
+// binaryFormats are the formats of --format that take counts as numbers:
+// the counts are not converted to decimal, which takes longer than counting
+// for counts of millions of digits, nor cached, as the cache holds decimals.
+var binaryFormats = map[string]bool{"gob": true, "raw": true}
+
+// gobRecord is the record of a count in the gob format.
+type gobRecord struct {
+	File      string
+	Count     *big.Int // nil on an error
+	Error     string
+	SHA256    string
+	Length    int
+	Version   string
+	Algorithm string
+	Flags     []string
+	DupOf     string
+}
+
+// gobFormatter writes the records of counts as a gob stream of gobRecord
+// values, for Go programs; big.Int encodes the counts as bytes.
+type gobFormatter struct {
+	enc *gob.Encoder
+}
+
+// Format implements Formatter. Only the records of counts are supported.
+func (f *gobFormatter) Format(v any) error {
+	r, ok := v.(resultRecord)
+	if !ok {
+		return fmt.Errorf("--format gob only supports the results of counts, not %T records", v)
+	}
+	return f.enc.Encode(gobRecord{
+		File:      r.File,
+		Count:     r.value,
+		Error:     r.Error,
+		SHA256:    r.SHA256,
+		Length:    r.Length,
+		Version:   r.Version,
+		Algorithm: r.Algorithm,
+		Flags:     r.Flags,
+		DupOf:     r.DupOf,
+	})
+}
+
+// Flush implements Formatter. The records are not buffered.
+func (f *gobFormatter) Flush() error {
+	return nil
+}
+
+// rawFormatter writes the counts in the binary form of decodeways.Result,
+// one after another (see decodeways.Result.ReadBinary). Like in the plain
+// format, errors only go to stderr.
+type rawFormatter struct {
+	w io.Writer
+}
+
+// Format implements Formatter. Only the records of counts are supported.
+func (f *rawFormatter) Format(v any) error {
+	r, ok := v.(resultRecord)
+	if !ok {
+		return fmt.Errorf("--format raw only supports the results of counts, not %T records", v)
+	}
+	if r.value == nil {
+		return nil // An error, reported on stderr
+	}
+	b, err := decodeways.Result{Count: r.value, Length: int64(r.Length)}.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = f.w.Write(b)
+	return err
+}
+
+// Flush implements Formatter. The records are not buffered.
+func (f *rawFormatter) Flush() error {
+	return nil
+}
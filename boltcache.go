@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets of a boltCache.
+var (
+	boltCounts = []byte("counts") // Key -> 8-byte store time and the count
+	boltOrder  = []byte("order")  // 8-byte store time and key -> nothing, oldest first
+	boltMeta   = []byte("meta")   // "size" -> total size of the entries
+)
+
+// boltSizeKey is the key of the total size in the meta bucket.
+var boltSizeKey = []byte("size")
+
+// boltCache is a persistent cache of counts by key in a bbolt database, so
+// that the counts of a server survive restarts.
+//
+// Entries expire ttl after they were stored, and when the entries take more
+// than maxSize bytes (key plus count), the oldest are evicted. The order
+// bucket indexes the entries by store time, so both find their victims at
+// its start. A nil cache stores nothing.
+type boltCache struct {
+	db      *bolt.DB
+	ttl     time.Duration // 0 for entries that never expire
+	maxSize int64         // 0 for no limit
+}
+
+// openBoltCache opens or creates a persistent cache.
+//
+// Parameters:
+//   - path: Path of the database file
+//   - ttl: Lifetime of an entry, 0 for no expiry
+//   - maxSize: Maximum total size of the entries in bytes, 0 for no limit
+//
+// Returns:
+//   - *boltCache: The cache
+//   - error: An error if the database can not be opened
+func openBoltCache(path string, ttl time.Duration, maxSize int64) (*boltCache, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltCounts, boltOrder, boltMeta} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltCache{db: db, ttl: ttl, maxSize: maxSize}, nil
+}
+
+// expired tells whether an entry stored at t has expired.
+func (c *boltCache) expired(t time.Time) bool {
+	return c.ttl > 0 && time.Since(t) > c.ttl
+}
+
+// get returns the count of a key, unless it expired.
+func (c *boltCache) get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	var r string
+	c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltCounts).Get([]byte(key))
+		if len(v) < 8 || c.expired(time.Unix(0, int64(binary.BigEndian.Uint64(v)))) {
+			return nil
+		}
+		r = string(v[8:])
+		return nil
+	})
+	return r, r != ""
+}
+
+// put stores the count of a key, evicting expired entries and, if the cache
+// is over its size, the oldest ones.
+//
+// Returns:
+//   - error: An error if the database can not be written
+func (c *boltCache) put(key, value string) error {
+	if c == nil {
+		return nil
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		counts, order, meta := tx.Bucket(boltCounts), tx.Bucket(boltOrder), tx.Bucket(boltMeta)
+		size := int64(0)
+		if v := meta.Get(boltSizeKey); len(v) == 8 {
+			size = int64(binary.BigEndian.Uint64(v))
+		}
+
+		if old := counts.Get([]byte(key)); old != nil {
+			order.Delete(append(old[:8:8], key...))
+			size -= int64(len(key) + len(old) - 8)
+		}
+		now := make([]byte, 8)
+		binary.BigEndian.PutUint64(now, uint64(time.Now().UnixNano()))
+		if err := counts.Put([]byte(key), append(now, value...)); err != nil {
+			return err
+		}
+		if err := order.Put(append(now, key...), nil); err != nil {
+			return err
+		}
+		size += int64(len(key) + len(value))
+
+		// The oldest entries come first in the order bucket
+		cur := order.Cursor()
+		for k, _ := cur.First(); k != nil; k, _ = cur.First() {
+			t := time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+			if !c.expired(t) && (c.maxSize == 0 || size <= c.maxSize) {
+				break
+			}
+			victim := k[8:]
+			size -= int64(len(victim) + len(counts.Get(victim)) - 8)
+			if err := counts.Delete(victim); err != nil {
+				return err
+			}
+			if err := cur.Delete(); err != nil {
+				return err
+			}
+		}
+
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, uint64(size))
+		return meta.Put(boltSizeKey, v)
+	})
+}
+
+// Close closes the database.
+func (c *boltCache) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.db.Close()
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheDir is a directory of cache files bounded in size: once the files
+// exceed the limit, the least recently used ones are removed. Reading a file
+// touches its modification time, which thus tells when it was last used.
+//
+// Scanning the directory after every write would cost more than most counts,
+// so it is only scanned on the first write of a process and then whenever a
+// sixteenth of the limit was written since; several processes sharing the
+// directory may thus take it a little over the limit. It is safe for
+// concurrent use.
+type cacheDir struct {
+	path string
+	max  int64 // Maximum total size of the files in bytes, 0 for no limit
+
+	mu      sync.Mutex
+	written int64 // Bytes written since the directory was last scanned
+	scanned bool  // Whether the directory was scanned by this process
+}
+
+// openCacheDir returns the cache directory of a --*-cache flag.
+//
+// Parameters:
+//   - path: The directory, empty if the cache is disabled
+//   - size: The limit of its total size, e.g. "1G", or "0" for no limit
+//
+// Returns:
+//   - *cacheDir: The cache directory, nil if path is empty
+//   - error: An error if the size is invalid
+func openCacheDir(path, size string) (*cacheDir, error) {
+	if path == "" {
+		return nil, nil
+	}
+	d := &cacheDir{path: path}
+	if size != "0" {
+		var err error
+		if d.max, err = parseSize(size); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// read returns the contents of a cache file, marking it as used.
+//
+// Parameters:
+//   - name: The name of the file in the directory
+//
+// Returns:
+//   - []byte: The contents of the file
+//   - error: An error if the file can not be read
+func (d *cacheDir) read(name string) ([]byte, error) {
+	path := filepath.Join(d.path, name)
+	data, err := os.ReadFile(path)
+	if err == nil && d.max > 0 {
+		now := time.Now()
+		os.Chtimes(path, now, now)
+	}
+	return data, err
+}
+
+// write writes a cache file atomically (see writeFileAtomic), then evicts
+// the least recently used files if the directory is over its limit. The
+// cache is best effort, so errors are ignored.
+//
+// Parameters:
+//   - name: The name of the file in the directory
+//   - chunks: The contents of the file
+func (d *cacheDir) write(name string, chunks ...[]byte) {
+	if writeFileAtomic(filepath.Join(d.path, name), chunks...) != nil || d.max <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range chunks {
+		d.written += int64(len(c))
+	}
+	if d.scanned && d.written < d.max/16 {
+		return
+	}
+	d.written, d.scanned = 0, true
+	d.evict()
+}
+
+// evict removes the least recently used files until the directory is a
+// sixteenth under its limit, leaving room for the next writes. The lock
+// must be held.
+func (d *cacheDir) evict() {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return
+	}
+	type file struct {
+		name string
+		size int64
+		used time.Time
+	}
+	var files []file
+	total := int64(0)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), "tmp-") {
+			continue // Being written by writeFileAtomic
+		}
+		if fi, err := e.Info(); err == nil {
+			files = append(files, file{e.Name(), fi.Size(), fi.ModTime()})
+			total += fi.Size()
+		}
+	}
+	if total <= d.max {
+		return
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].used.Before(files[b].used) })
+	for _, f := range files {
+		if total <= d.max-d.max/16 {
+			break
+		}
+		if os.Remove(filepath.Join(d.path, f.name)) == nil {
+			total -= f.size
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"task1/decodeways"
+)
+
+// check validates an input under the options of the counter without
+// counting it (--check). The cluster scan only collects the factors without
+// multiplying them, and the DPs of wildcards and of codes longer than two
+// digits only track which prefixes can be decoded, so no big number is
+// computed.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string, possibly with wildcards
+//
+// Returns:
+//   - error: The error countInput would return, nil if the input is valid
+func (c *counter) check(p []byte) error {
+	wild := hasWildcards(p) && !c.lenient
+	if wild && c.wide {
+		return errors.New("wildcards only support codes of up to two digits")
+	}
+	var err error
+	if !wild && !c.wide && !c.lenient && c.zeros != zerosSplit {
+		_, err = scanParallel(p, c.s, c.threads)
+	} else {
+		runs := [][2]int{{0, len(p)}}
+		if c.lenient {
+			runs = digitRuns(p)
+		}
+		if c.zeros == zerosSplit {
+			runs = splitStandalone(p, runs, c.s)
+		}
+		if len(runs) == 0 {
+			return errors.New("no digits in the input")
+		}
+		for _, r := range runs {
+			q := p[r[0]:r[1]]
+			if !wild && !c.wide {
+				if err = decodeways.ScanClusters(q, r[0], c.s, decodeways.Factors{}); err != nil {
+					break
+				}
+				continue
+			}
+			if wild {
+				err = checkWildcard(q, c.s)
+			} else {
+				err = checkWindow(q, c.codes)
+			}
+			if err != nil {
+				// Positions of the DPs are relative to the run
+				var se *decodeways.SyntaxError
+				if errors.As(err, &se) {
+					se.Pos += r[0]
+				}
+				break
+			}
+		}
+	}
+	if err != nil && c.zeros == zerosZero && digitError(err, p) {
+		return nil // The count is 0
+	}
+	if err != nil {
+		if c.maxErrors > 0 && !c.wide && !wild && !c.lenient {
+			if l := collectErrors(p, c.s, c.maxErrors); l != nil {
+				return l
+			}
+		}
+		return withLocation(err, p)
+	}
+	return nil
+}
+
+// checkWindow validates a digit string with codes of variable length, like
+// countWindow but tracking only whether each prefix can be decoded.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - r: The code set
+//
+// Returns:
+//   - error: An error at the position where decoding becomes impossible
+func checkWindow(p []byte, r codeSet) error {
+	if len(p) == 0 {
+		return errors.New("empty string")
+	}
+	m := r.width()
+	// reach[j]: whether the prefix ending j digits back can be decoded,
+	// reach[0] being the current position
+	reach := make([]bool, m+1)
+	reach[0] = true
+	last := 0 // Length of the longest decodable prefix
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return &decodeways.SyntaxError{Msg: "encountered non-digit character", Pos: i}
+		}
+		copy(reach[1:], reach[:m])
+		reach[0] = false
+		for l := 1; l <= m && l <= i+1; l++ {
+			if reach[l] && r.valid(p[i+1-l:i+1]) {
+				reach[0] = true
+				break
+			}
+		}
+		if reach[0] {
+			last = i + 1
+		} else if i+1-last >= m {
+			// No code can reach back to a decodable prefix any more
+			return &decodeways.SyntaxError{Msg: "string can not be decoded", Pos: i}
+		}
+	}
+	if !reach[0] {
+		return &decodeways.SyntaxError{Msg: "string can not be decoded", Pos: len(p) - 1}
+	}
+	return nil
+}
+
+// checkWildcard validates a digit string with wildcards, like
+// countWildcard but tracking only whether each prefix can be decoded under
+// some assignment of digits.
+//
+// Parameters:
+//   - p: Byte slice containing the pattern
+//   - sc: The letter mapping scheme
+//
+// Returns:
+//   - error: An error if no digit assignment can be decoded
+func checkWildcard(p []byte, sc *decodeways.Scheme) error {
+	sets, err := parsePattern(p)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		return errors.New("empty string")
+	}
+	prev2, prev1 := false, true // Whether ways(i-2) and ways(i-1) are non-zero
+	for i, s := range sets {
+		cur := prev1 && s.singles(sc) > 0 || i > 0 && prev2 && sets[i-1].pairs(s, sc) > 0
+		prev2, prev1 = prev1, cur
+	}
+	if !prev1 {
+		return errors.New("no digit assignment can be decoded")
+	}
+	return nil
+}
+
+// checkRecord is the record of a validated input.
+type checkRecord struct {
+	File  string `json:"file"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+
+	single bool // Whether the input is the only one
+}
+
+// plain implements plainRecord: "OK", or "<filename>: OK" if there are
+// several inputs.
+func (r checkRecord) plain() string {
+	switch {
+	case !r.Valid:
+		return ""
+	case r.single:
+		return "OK\n"
+	}
+	return r.File + ": OK\n"
+}
+
+// runCheck validates inputs without counting them (--check). Every input
+// gets a record (see checkRecord); the first problem of an invalid one is
+// also reported on stderr.
+//
+// Parameters:
+//   - c: The counter
+//   - files: Paths of the input files
+//   - f: The formatter of --format
+//
+// Returns:
+//   - int: The process exit code, that of the first file that failed (see
+//     exitCode)
+func runCheck(c *counter, files []string, f Formatter) int {
+	return runEach(files, c.maxLen, f, func(name string, p []byte, err error) (any, error) {
+		if err == nil {
+			err = c.check(p)
+		}
+		r := checkRecord{File: name, Valid: err == nil, single: len(files) == 1}
+		if err != nil {
+			r.Error, _, _ = strings.Cut(err.Error(), "\n") // One line, without the snippet
+		}
+		return r, err
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"task1/decodeways"
+)
+
+// checkpointPiece is how much of the input is scanned between two looks at
+// the clock.
+const checkpointPiece = 64 << 20
+
+// checkpoint is the content of a checkpoint file: the scan state and what
+// identifies the input and options it belongs to.
+type checkpoint struct {
+	File    string               `json:"file"`    // Absolute path of the input
+	Size    int64                `json:"size"`    // Size of the input
+	ModTime time.Time            `json:"mtime"`   // Modification time of the input
+	Options string               `json:"options"` // Options affecting the result
+	State   decodeways.ScanState `json:"state"`
+}
+
+// checkpointConfig are the options of a checkpointed run.
+type checkpointConfig struct {
+	path     string        // Checkpoint file
+	interval time.Duration // Time between two checkpoints
+	resume   bool          // Whether to continue from an existing checkpoint
+}
+
+// inputIdentity fills in the fields identifying the input of a checkpoint.
+func inputIdentity(filename, options string) (checkpoint, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return checkpoint{}, err
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return checkpoint{}, err
+	}
+	return checkpoint{File: abs, Size: fi.Size(), ModTime: fi.ModTime(), Options: options}, nil
+}
+
+// loadCheckpoint reads a checkpoint and checks that it belongs to the input.
+//
+// Parameters:
+//   - path: The checkpoint file
+//   - id: The identity of the input, see inputIdentity
+//
+// Returns:
+//   - decodeways.ScanState: The saved scan state
+//   - error: An error if the checkpoint can not be read or is for another input
+func loadCheckpoint(path string, id checkpoint) (decodeways.ScanState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return decodeways.ScanState{}, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return decodeways.ScanState{}, fmt.Errorf("parsing checkpoint '%s': %w", path, err)
+	}
+	if cp.File != id.File || cp.Size != id.Size || !cp.ModTime.Equal(id.ModTime) || cp.Options != id.Options {
+		return decodeways.ScanState{}, fmt.Errorf("checkpoint '%s' is for another input or other options", path)
+	}
+	if cp.State.Offset < 0 || cp.State.Offset > cp.Size || cp.State.Factors == nil {
+		return decodeways.ScanState{}, fmt.Errorf("checkpoint '%s' is damaged", path)
+	}
+	return cp.State, nil
+}
+
+// countCheckpointed counts the decodings of a file, periodically saving the
+// scan state so that an interrupted run can be resumed.
+//
+// The scan runs sequentially through a decodeways.Scanner; every interval
+// the state (offset, previous digit, open cluster and the factors so far,
+// which stand for the partial product) is written to the checkpoint file,
+// atomically so that a crash never leaves a damaged one. With resume, the
+// scan continues from the saved offset. The checkpoint is removed once the
+// scan has finished.
+//
+// Parameters:
+//   - c: The counter, which must use the cluster algorithm on the whole
+//     input
+//   - filename: Path to the input file
+//   - p: The content of the file
+//   - cfg: The checkpoint options
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if the input is invalid or the checkpoint unusable
+func countCheckpointed(c *counter, filename string, p []byte, cfg checkpointConfig) (*big.Int, error) {
+	if c.wide || c.mod != 0 || c.atLeast != nil || c.lenient || c.zeros != zerosError || hasWildcards(p) {
+		return big.NewInt(0), errors.New("checkpoints are only supported for codes of up to two digits without --mod, --at-least, --lenient or --zeros zero|split")
+	}
+	id, err := inputIdentity(filename, c.options)
+	if err != nil {
+		return big.NewInt(0), err
+	}
+
+	sc := decodeways.NewScanner(nil, c.s)
+	off := int64(0)
+	if cfg.resume {
+		st, err := loadCheckpoint(cfg.path, id)
+		if err != nil {
+			return big.NewInt(0), err
+		}
+		sc.Resume(st)
+		off = st.Offset
+	}
+
+	start := time.Now()
+	bar := newProgressBar(int64(len(p)))
+	bar.add(off)
+	scans.begin(int64(len(p)))
+	found := sc.State().Factors // Factors already reported to the status
+	scans.add(off, found)
+	last := time.Now()
+	for off < int64(len(p)) {
+		end := min(off+checkpointPiece, int64(len(p)))
+		if err := sc.Feed(p[off:end]); err != nil {
+			bar.finish()
+			return big.NewInt(0), err
+		}
+		bar.add(end - off)
+		st := sc.State()
+		piece := decodeways.Factors{} // Factors of the clusters closed in this piece
+		for f, c := range st.Factors {
+			if c > found[f] {
+				piece[f] = c - found[f]
+			}
+		}
+		scans.add(end-off, piece)
+		found = st.Factors
+		off = end
+		if time.Since(last) >= cfg.interval && off < int64(len(p)) {
+			id.State = sc.State()
+			data, err := json.Marshal(id)
+			if err == nil {
+				err = writeFileAtomic(cfg.path, data)
+			}
+			if err != nil {
+				bar.finish()
+				return big.NewInt(0), fmt.Errorf("writing checkpoint: %w", err)
+			}
+			last = time.Now()
+		}
+	}
+	bar.finish()
+	timings.record("scan", start)
+	logScanned(len(p), found, start)
+
+	state := sc.State()
+	if err := checkMemory(memoryEstimate(state.Factors, len(p))); err != nil {
+		return big.NewInt(0), err
+	}
+	start = time.Now()
+	x, err := sc.Result()
+	timings.record("multiply", start)
+	if err == nil {
+		slog.Info("multiplied", "factors", len(state.Factors), "bits", x.BitLen(), "duration", time.Since(start))
+	}
+	if err == nil {
+		os.Remove(cfg.path)
+	}
+	return x, err
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"task1/decodeways"
+)
+
+// saveCheckpoint writes the checkpoint of a run interrupted after the first
+// n bytes of the input, as countCheckpointed would.
+func saveCheckpoint(t *testing.T, c *counter, filename, path string, p []byte, n int) {
+	t.Helper()
+	id, err := inputIdentity(filename, c.options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := decodeways.NewScanner(nil, c.s)
+	if err := sc.Feed(p[:n]); err != nil {
+		t.Fatal(err)
+	}
+	id.State = sc.State()
+	data, err := json.Marshal(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "input.txt")
+	// Clusters of various sizes, with zeros and gaps between them
+	p := []byte(strings.Repeat("1121026311110926121212127", 40))
+	if err := os.WriteFile(filename, p, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := newCounter(countOptions{alphabet: 26, threads: 1, zeros: zerosError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := getPossibleCombinations(p, c.s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := checkpointConfig{path: filepath.Join(dir, "scan.checkpoint"), interval: time.Minute}
+
+	x, err := countCheckpointed(c, filename, p, cfg)
+	if err != nil || x.Cmp(want) != 0 {
+		t.Fatalf("countCheckpointed = %v, %v, want %v", x, err, want)
+	}
+
+	// Interrupted inside clusters, between them and at both ends, the resumed
+	// runs count as the uninterrupted one and remove the checkpoint
+	cfg.resume = true
+	for _, n := range []int{0, 1, 2, 5, 13, 24, 25, 26, 500, len(p) - 1, len(p)} {
+		saveCheckpoint(t, c, filename, cfg.path, p, n)
+		x, err := countCheckpointed(c, filename, p, cfg)
+		if err != nil || x.Cmp(want) != 0 {
+			t.Errorf("resumed at %d: countCheckpointed = %v, %v, want %v", n, x, err, want)
+		}
+		if _, err := os.Stat(cfg.path); !os.IsNotExist(err) {
+			t.Errorf("resumed at %d: the checkpoint is left behind (%v)", n, err)
+		}
+	}
+}
+
+func TestCheckpointMismatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "input.txt")
+	p := []byte("1121026311110926")
+	if err := os.WriteFile(filename, p, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := newCounter(countOptions{alphabet: 26, threads: 1, zeros: zerosError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := checkpointConfig{path: filepath.Join(dir, "scan.checkpoint"), interval: time.Minute, resume: true}
+
+	// No checkpoint to resume from
+	if _, err := countCheckpointed(c, filename, p, cfg); err == nil {
+		t.Error("countCheckpointed resumes without a checkpoint")
+	}
+
+	// A checkpoint of other options
+	zb, err := newCounter(countOptions{alphabet: 26, zeroBased: true, threads: 1, zeros: zerosError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveCheckpoint(t, zb, filename, cfg.path, p, 8)
+	if _, err := countCheckpointed(c, filename, p, cfg); err == nil || !strings.Contains(err.Error(), "other options") {
+		t.Errorf("countCheckpointed resumes from the checkpoint of other options (%v)", err)
+	}
+
+	// A checkpoint of the input before it changed
+	saveCheckpoint(t, c, filename, cfg.path, p, 8)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := countCheckpointed(c, filename, p, cfg); err == nil {
+		t.Error("countCheckpointed resumes from the checkpoint of a changed input")
+	}
+
+	// A damaged checkpoint
+	if err := os.WriteFile(cfg.path, []byte(`{"file": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := countCheckpointed(c, filename, p, cfg); err == nil {
+		t.Error("countCheckpointed resumes from a damaged checkpoint")
+	}
+
+	// Inputs the Scanner can not count
+	cfg.resume = false
+	if _, err := countCheckpointed(c, filename, []byte("1*2"), cfg); err == nil {
+		t.Error("countCheckpointed accepts wildcards")
+	}
+	lenient, err := newCounter(countOptions{alphabet: 26, threads: 1, lenient: true, zeros: zerosError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := countCheckpointed(lenient, filename, p, cfg); err == nil {
+		t.Error("countCheckpointed accepts --lenient")
+	}
+	for _, zeros := range []string{zerosZero, zerosSplit} {
+		z, err := newCounter(countOptions{alphabet: 26, threads: 1, zeros: zeros})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := countCheckpointed(z, filename, p, cfg); err == nil {
+			t.Errorf("countCheckpointed accepts --zeros %s", zeros)
+		}
+	}
+}
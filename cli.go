@@ -0,0 +1,291 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// command is a subcommand of decode-ways.
+type command struct {
+	name    string
+	usage   string // Arguments, as in "decode-ways <name> <usage>"
+	summary string // One-line description
+	run     func(args []string) int
+}
+
+// commands lists the subcommands in the order of the help. It is filled in
+// by init, as runCompletion refers to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"count", "[flags] <filename>...", "count the decodings of files (the default)", runCount},
+		{"enumerate", "[--match <pattern>] [--limit <n>] <filename>", "list the decodings of a file", runEnumerate},
+		{"verify", "<filename> <letters>", "check that letters are a decoding of a file", runVerify},
+		{"compare", "[flags] <a> <b>", "compare the counts of two files", runCompare},
+		{"encode", "[--count] <filename>", "encode letters into digits", runEncode},
+		{"construct", "--count <n>", "build an input with exactly n decodings", runConstruct},
+		{"gen", "[--size 1M] [--profile dense | --clusters <dist>] | --count <n> [--with-answers <file>]", "write a random input or test vectors", runGen},
+		{"bench", "[--sizes 1M,100M] [--profiles dense,sparse,zeros]", "benchmark synthetic inputs", runBench},
+		{"loadtest", "--target <url> [--qps 500] [--size 1K-1M] [--duration 30s]", "drive a server with synthetic inputs and report latencies", runLoadtest},
+		{"selftest", "[-v]", "check the binary against known answers", runSelftest},
+		{"serve", "[--addr :8080] [--cache-size 64M] [--grpc-addr :9090]", "serve counts over HTTP, WebSocket and gRPC", runServe},
+		{"client", "--server <address> <filename>...", "count files on a remote server", runClient},
+		{"daemon", "--socket <path>", "serve counts over a Unix socket", runDaemon},
+		{"watchdir", "<dir> --done <dir>", "count the files dropped into a directory", runWatchdir},
+		{"completion", "bash|zsh|fish", "print a shell completion script", runCompletion},
+	}
+}
+
+// findCommand returns the subcommand of a name, or nil if there is none.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// listFlags is set by the hidden "__flags" command of the completion scripts:
+// parseFlags then prints the flags of the subcommand instead of running it.
+var listFlags bool
+
+// parseFlags parses the flags of a subcommand, with the defaults of the
+// config file (see applyConfig).
+//
+// Parameters:
+//   - fs: The flag set of the subcommand
+//   - args: The arguments after the subcommand name
+func parseFlags(fs *flag.FlagSet, args []string) {
+	if listFlags {
+		fs.VisitAll(func(f *flag.Flag) { fmt.Println("--" + f.Name) })
+		os.Exit(0)
+	}
+	fs.Init(fs.Name(), flag.ContinueOnError) // Exit with 1, as 2 is an invalid input
+	if err := applyConfig(fs); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		os.Exit(1)
+	}
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		os.Exit(1)
+	}
+}
+
+// usage prints the command-line help of the count subcommand to stderr, in
+// the language of the messages (see tr).
+func usage(fs *flag.FlagSet) {
+	word := tr("Usage:")
+	for i, cmd := range commands {
+		prefix := strings.Repeat(" ", utf8.RuneCountInString(word)) + " decode-ways "
+		if i == 0 {
+			prefix = word + " decode-ways [count] "
+		}
+		args := cmd.name + " " + cmd.usage
+		if cmd.name == "count" {
+			args = cmd.usage
+		}
+		fmt.Fprintln(os.Stderr, prefix+args)
+	}
+	fmt.Fprintln(os.Stderr, tr("Example:"), "decode-ways test2.txt")
+	fmt.Fprintln(os.Stderr, "\n"+tr("Commands:"))
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", cmd.name, tr(cmd.summary))
+	}
+	fmt.Fprintln(os.Stderr, "\n"+tr("Flags:"))
+	fs.VisitAll(func(f *flag.Flag) { f.Usage = tr(f.Usage) })
+	fs.PrintDefaults()
+}
+
+// runEnumerate implements the "enumerate" subcommand: it prints the decodings
+// of a file, one per line, in lexicographic order of their codes.
+//
+// Usage: decode-ways enumerate [--match <pattern>] [--limit <n>] <filename>
+//
+// Returns:
+//   - int: The process exit code
+func runEnumerate(args []string) int {
+	fs := flag.NewFlagSet("enumerate", flag.ExitOnError)
+	match := fs.String("match", "*", "only list decodings matching a glob `pattern` (letters, '?' and '*')")
+	limit := fs.Int("limit", 100, "print at most `n` decodings")
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways enumerate [--match <pattern>] [--limit <n>] <filename>")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways enumerate --match 'A*' test.txt")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 1 || *limit < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	g, err := newGlobMatcher(*match)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	p, err := readInput(fs.Arg(0), 0)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error %v\n", trError(err))))
+		return exitIO
+	}
+	d, err := listMatching(p, g, *limit)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+		return exitCode(err)
+	}
+	records := make([]any, len(d))
+	for i, s := range d {
+		records[i] = decodingRecord{Decoding: s}
+	}
+	return formatAll(f, records...)
+}
+
+// decodingRecord is the record of a decoding listed by enumerate.
+type decodingRecord struct {
+	Decoding string `json:"decoding"`
+}
+
+// plain implements plainRecord.
+func (r decodingRecord) plain() string {
+	return r.Decoding + "\n"
+}
+
+// runGen implements the "gen" subcommand: it writes a random valid input of
+// one of the bench profiles or of a cluster size distribution (see
+// clusterInput) to stdout or, with --count, a set of short test vectors (see
+// writeVectors).
+//
+// Usage: decode-ways gen [--size 1M] [--profile dense] [--seed n]
+//
+//	decode-ways gen [--size 1M] --clusters geometric:4 [--zero-density 0.1]
+//
+//	decode-ways gen --count 1000 [--max-len 50] [--with-answers vectors.tsv]
+//
+// Returns:
+//   - int: The process exit code
+func runGen(args []string) int {
+	names := make([]string, 0, len(benchProfiles))
+	for name := range benchProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	size := fs.String("size", "1M", "length of the input in `bytes` (K, M and G suffixes)")
+	profile := fs.String("profile", "dense", "input `profile`: "+strings.Join(names, ", "))
+	clusters := fs.String("clusters", "", "cluster size `distribution` instead of a profile: fixed:N, uniform:A-B or geometric:M")
+	zeroDensity := fs.Float64("zero-density", 0, "share of the clusters of --clusters closed by a '0'")
+	seed := fs.Int64("seed", 0, "seed of the random generator (0 for a random seed)")
+	count := fs.Int("count", 0, "write `n` short test vectors, one per line, instead of one input")
+	maxLen := fs.Int("max-len", 50, "maximum `length` of a test vector")
+	answers := fs.String("with-answers", "", "write the test vectors with their counts as TSV to `file`")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways gen [--size 1M] [--profile dense] [--seed n]")
+		fmt.Fprintln(os.Stderr, "       decode-ways gen [--size 1M] --clusters geometric:4 [--zero-density 0.1]")
+		fmt.Fprintln(os.Stderr, "       decode-ways gen --count 1000 [--max-len 50] [--with-answers vectors.tsv]")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways gen --size 100M --profile sparse > input.txt")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	if *count > 0 || *answers != "" {
+		return genVectors(*count, *maxLen, *seed, *answers)
+	}
+
+	n, err := parseSize(*size)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --size: %v\n", trError(err))))
+		return 1
+	}
+	var p []byte
+	if *clusters != "" {
+		dist, err := parseClusterDist(*clusters)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return 1
+		}
+		if *zeroDensity < 0 || *zeroDensity > 1 {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --zero-density must be between 0 and 1\n")))
+			return 1
+		}
+		p = clusterInput(n, dist, *zeroDensity, *seed)
+	} else {
+		tokens, ok := benchProfiles[*profile]
+		if !ok {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: unknown profile %q\n", *profile)))
+			return 1
+		}
+		p = benchInput(n, tokens, *seed)
+	}
+	if _, err := os.Stdout.Write(p); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	return 0
+}
+
+// genVectors writes the test vectors of "gen --count".
+//
+// Parameters:
+//   - count: Number of vectors
+//   - maxLen: Maximum length of a vector
+//   - seed: The seed of the random generator
+//   - answers: File to write the vectors with their counts to, empty to
+//     write the vectors alone to stdout
+//
+// Returns:
+//   - int: The process exit code
+func genVectors(count, maxLen int, seed int64, answers string) int {
+	if count <= 0 || maxLen <= 0 {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --count and --max-len must be positive\n")))
+		return 1
+	}
+	if answers == "" {
+		if err := writeVectors(os.Stdout, count, maxLen, seed, false); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return 1
+		}
+		return 0
+	}
+	f, err := os.Create(answers)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	err = writeVectors(f, count, maxLen, seed, true)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error writing %s: %v\n", answers, trError(err))))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,370 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// clientChunkSize is the size of the chunks of an input sent over gRPC.
+const clientChunkSize = 64 << 10
+
+// remoteError is the error of a count on a server, with the exit code of its
+// class (see exitCode).
+type remoteError struct {
+	msg  string
+	code int
+}
+
+// Error implements the error interface.
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+// remoteServer sends inputs to a server started with "decode-ways serve",
+// over HTTP or gRPC.
+type remoteServer struct {
+	url    string           // URL of POST /count, "" for gRPC
+	hc     *http.Client     // Client of HTTP servers
+	cc     *grpc.ClientConn // Connection to gRPC servers
+	apiKey string           // API key sent as a bearer token, "" for none
+}
+
+// dialServer prepares the connection to a server. The protocol follows from
+// the address: "http://" and "https://" URLs are HTTP servers, "grpcs://host:port"
+// a gRPC server with TLS, and "grpc://host:port" or a plain "host:port" a gRPC
+// server without.
+//
+// Parameters:
+//   - server: The address of the server
+//   - apiKey: The API key, empty for none
+//   - caFile: PEM file of the certificate authorities trusted for TLS, empty
+//     for those of the system
+//
+// Returns:
+//   - *remoteServer: The server
+//   - error: An error if the address or the CA file is invalid
+func dialServer(server, apiKey, caFile string) (*remoteServer, error) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+	}
+	s := &remoteServer{apiKey: apiKey}
+	if strings.HasPrefix(server, "http://") || strings.HasPrefix(server, "https://") {
+		s.url = strings.TrimSuffix(server, "/") + "/count"
+		if _, err := http.NewRequest(http.MethodPost, s.url, nil); err != nil {
+			return nil, err
+		}
+		s.hc = &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tc}}
+		return s, nil
+	}
+	creds := insecure.NewCredentials()
+	if target, ok := strings.CutPrefix(server, "grpcs://"); ok {
+		server, creds = target, credentials.NewTLS(tc)
+	} else {
+		server = strings.TrimPrefix(server, "grpc://")
+	}
+	cc, err := grpc.NewClient(server, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	s.cc = cc
+	return s, nil
+}
+
+// Close closes the connection to the server.
+func (s *remoteServer) Close() error {
+	if s.cc != nil {
+		return s.cc.Close()
+	}
+	return nil
+}
+
+// count sends an input to the server and waits for its count. The input is
+// streamed, so it is never held in memory by the client.
+//
+// Parameters:
+//   - ctx: The context of the request, with the deadline of --timeout
+//   - r: The input
+//   - size: The size of the input, -1 if unknown
+//
+// Returns:
+//   - string: The count in decimal
+//   - string: The algorithm the server used, "" if it does not tell
+//   - error: A remoteError if the server failed or refused the count
+func (s *remoteServer) count(ctx context.Context, r io.Reader, size int64) (string, string, error) {
+	if s.cc != nil {
+		return s.countGRPC(ctx, r)
+	}
+	return s.countHTTP(ctx, r, size)
+}
+
+// countHTTP sends an input to POST /count.
+func (s *remoteServer) countHTTP(ctx context.Context, r io.Reader, size int64) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, r)
+	if err != nil {
+		return "", "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "text/plain")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	resp, err := s.hc.Do(req)
+	if err != nil {
+		var pe *os.PathError
+		if errors.As(err, &pe) {
+			return "", "", pe // Reading the input failed, not the server
+		}
+		return "", "", &remoteError{msg: err.Error(), code: transportCode(ctx)}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", &remoteError{msg: err.Error(), code: transportCode(ctx)}
+	}
+	if resp.StatusCode == http.StatusOK {
+		return strings.TrimSpace(string(body)), "", nil
+	}
+	var he httpError
+	if json.Unmarshal(body, &he) != nil || he.Error == "" {
+		he.Error = resp.Status
+	}
+	code := exitIO
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		code = exitInvalid
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		code = exitLimit
+	case http.StatusGatewayTimeout:
+		code = exitTimeout
+	case http.StatusUnauthorized, http.StatusForbidden:
+		code = 1
+	}
+	return "", "", &remoteError{msg: strings.TrimPrefix(he.Error, "Error decoding: "), code: code}
+}
+
+// countGRPC streams an input to the CountResult method.
+func (s *remoteServer) countGRPC(ctx context.Context, r io.Reader) (string, string, error) {
+	if s.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+s.apiKey)
+	}
+	stream, err := s.cc.NewStream(ctx, &grpc.StreamDesc{StreamName: "CountResult", ClientStreams: true}, "/"+countServiceName+"/CountResult")
+	if err != nil {
+		return "", "", grpcError(err)
+	}
+	for {
+		chunk := make([]byte, clientChunkSize) // Not reused, SendMsg may keep it
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			if err := stream.SendMsg(wrapperspb.Bytes(chunk[:n])); errors.Is(err, io.EOF) {
+				break // The server ended the call, RecvMsg tells why
+			} else if err != nil {
+				return "", "", grpcError(err)
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return "", "", err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return "", "", grpcError(err)
+	}
+	m := dynamicpb.NewMessage(resultDesc)
+	if err := stream.RecvMsg(m); err != nil {
+		return "", "", grpcError(err)
+	}
+	fields := resultDesc.Fields()
+	if msg := m.Get(fields.ByName("error")).String(); msg != "" {
+		return "", "", &remoteError{msg: msg, code: exitInvalid}
+	}
+	return m.Get(fields.ByName("count")).String(), m.Get(fields.ByName("algorithm")).String(), nil
+}
+
+// grpcError converts the status error of a gRPC call into a remoteError.
+func grpcError(err error) error {
+	st, _ := status.FromError(err)
+	code := exitIO
+	switch st.Code() {
+	case codes.InvalidArgument:
+		code = exitInvalid
+	case codes.ResourceExhausted:
+		code = exitLimit
+	case codes.DeadlineExceeded:
+		code = exitTimeout
+	case codes.Unauthenticated, codes.PermissionDenied:
+		code = 1
+	}
+	return &remoteError{msg: strings.TrimPrefix(st.Message(), "Error decoding: "), code: code}
+}
+
+// transportCode returns the exit code of a request that got no answer:
+// exitTimeout past the deadline of --timeout, else exitIO.
+func transportCode(ctx context.Context) int {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return exitTimeout
+	}
+	return exitIO
+}
+
+// hashingReader hashes and measures an input as it is read.
+type hashingReader struct {
+	r io.Reader
+	h hash.Hash // nil if the input is not hashed
+	n int
+}
+
+// Read implements io.Reader.
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.h != nil {
+		r.h.Write(p[:n])
+	}
+	r.n += n
+	return n, err
+}
+
+// sendFile sends a file to the server and describes it for its record.
+//
+// Parameters:
+//   - s: The server
+//   - rw: The writer of the records, which tells whether to hash the file
+//   - filename: Path of the file
+//   - timeout: Deadline of the count, 0 for none
+//
+// Returns:
+//   - countValue: The count
+//   - inputInfo: The file: its length, and its SHA-256 if hashed
+//   - error: An error if the file can not be read or the count failed
+func sendFile(s *remoteServer, rw *resultWriter, filename string, timeout time.Duration) (countValue, inputInfo, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return countValue{}, inputInfo{}, err
+	}
+	defer f.Close()
+	size := int64(-1)
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+		size = fi.Size()
+	}
+	hr := &hashingReader{r: f}
+	if rw.provenance || rw.report != nil {
+		hr.h = sha256.New()
+	}
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+	x, algorithm, err := s.count(ctx, hr, size)
+	in := inputInfo{length: hr.n, algorithm: algorithm}
+	if hr.h != nil && err == nil {
+		in.sum = hr.h.Sum(nil)
+	}
+	if err != nil {
+		return countValue{}, in, err
+	}
+	if rw.binary {
+		v, _ := new(big.Int).SetString(x, 10)
+		return countValue{x: v}, in, nil
+	}
+	return countValue{s: x}, in, nil
+}
+
+// runClient implements the "client" subcommand: it sends files to a server
+// started with "decode-ways serve" and prints their counts like the count
+// command, so a thin client can offload big jobs. The files are streamed, in
+// chunks over gRPC, so they need not fit in the memory of the client.
+//
+// Usage: decode-ways client --server <address> [--api-key <key>] <filename>...
+//
+// Returns:
+//   - int: The process exit code, as for the count command
+func runClient(args []string) int {
+	fs := flag.NewFlagSet("client", flag.ExitOnError)
+	server := fs.String("server", "", "`address` of the server: host:port or grpcs://host:port for gRPC, http(s)://host:port for HTTP")
+	apiKey := fs.String("api-key", os.Getenv("DECODE_WAYS_API_KEY"), "API `key` sent as a bearer token (default $DECODE_WAYS_API_KEY)")
+	caFile := fs.String("ca-cert", "", "PEM `file` of the certificate authorities to trust for TLS (default those of the system)")
+	timeout := fs.Duration("timeout", 0, "give up waiting for a count after this time (default no limit)")
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways client --server <address> [--api-key <key>] <filename>...")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways client --server localhost:9090 test2.txt")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if *server == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+	rw, err := newResultWriter(os.Stdout, *format, fs, "")
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	s, err := dialServer(*server, *apiKey, *caFile)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --server: %v\n", trError(err))))
+		return 1
+	}
+	defer s.Close()
+
+	files := fs.Args()
+	code, failed := 0, 0
+	for _, name := range files {
+		start := time.Now()
+		count, in, err := sendFile(s, rw, name, *timeout)
+		if werr := rw.write(name, count, err, in, len(files) == 1, time.Since(start)); werr != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(werr))))
+			return exitIO
+		}
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding %s: %v\n", name, trError(err))))
+			if code == 0 {
+				code = exitCode(err)
+			}
+			failed++
+		}
+	}
+	if failed > 0 && failed < len(files) {
+		code = exitPartial
+	}
+	if c := formatAll(rw.f); c != 0 {
+		return c
+	}
+	return code
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// clusterDist is a distribution of the number of pairs of a cluster.
+type clusterDist struct {
+	kind   string  // "fixed", "uniform" or "geometric"
+	lo, hi int64   // Bounds of fixed and uniform
+	mean   float64 // Mean of geometric
+}
+
+// parseClusterDist parses a cluster size distribution:
+//
+//	fixed:N      every cluster has N pairs (fixed:1G for one giant cluster)
+//	uniform:A-B  uniform between A and B pairs
+//	geometric:M  geometric with a mean of M pairs
+//
+// Sizes take the K, M and G suffixes of parseSize.
+//
+// Parameters:
+//   - s: The distribution string
+//
+// Returns:
+//   - clusterDist: The distribution
+//   - error: An error if s is not a distribution
+func parseClusterDist(s string) (clusterDist, error) {
+	kind, arg, _ := strings.Cut(s, ":")
+	size := func(s string) (int64, error) {
+		if s == "0" {
+			return 0, nil
+		}
+		return parseSize(s)
+	}
+	d := clusterDist{kind: kind}
+	var err error
+	switch kind {
+	case "fixed":
+		d.lo, err = size(arg)
+		d.hi = d.lo
+	case "uniform":
+		a, b, ok := strings.Cut(arg, "-")
+		if d.lo, err = size(a); err == nil {
+			d.hi, err = size(b)
+		}
+		if err == nil && (!ok || d.lo > d.hi) {
+			err = fmt.Errorf("invalid range %q", arg)
+		}
+	case "geometric":
+		d.mean, err = strconv.ParseFloat(arg, 64)
+		if err == nil && d.mean < 0 {
+			err = fmt.Errorf("negative mean %q", arg)
+		}
+	default:
+		err = fmt.Errorf("unknown distribution %q (fixed, uniform or geometric)", kind)
+	}
+	if err != nil {
+		return clusterDist{}, fmt.Errorf("--clusters: %v", err)
+	}
+	return d, nil
+}
+
+// draw returns a random cluster size of the distribution.
+func (d clusterDist) draw(rnd *rand.Rand) int64 {
+	switch d.kind {
+	case "uniform":
+		return d.lo + rnd.Int63n(d.hi-d.lo+1)
+	case "geometric":
+		n, extend := int64(0), d.mean/(d.mean+1)
+		for rnd.Float64() < extend {
+			n++
+		}
+		return n
+	}
+	return d.lo
+}
+
+// clusterInput generates a synthetic input whose clusters follow a size
+// distribution, so a benchmark can target one regime of the algorithm: a
+// single giant cluster (one huge Fibonacci number) or many tiny ones (a
+// deep product tree).
+//
+// A cluster of n pairs is n digits of '1' and '2' closed by a digit '3'-'9'
+// that pairs with the one before it but starts no pair itself, so the next
+// cluster starts right after it; a cluster of 0 pairs is a lone digit
+// '3'-'9'. With probability zeroDensity a cluster is closed by a '0' instead,
+// which takes the last digit out of the cluster as code 10 or 20 (one more
+// digit of '1' and '2' keeps the cluster at n pairs). No cluster ends in a
+// digit that starts a pair, so clusters never merge, and the input is cut at
+// the given size, which leaves it valid.
+//
+// Parameters:
+//   - size: Length of the input in bytes
+//   - dist: The distribution of cluster sizes
+//   - zeroDensity: Probability of a cluster closed by a '0'
+//   - seed: The seed of the random generator
+//
+// Returns:
+//   - []byte: The generated digit string
+func clusterInput(size int64, dist clusterDist, zeroDensity float64, seed int64) []byte {
+	rnd := rand.New(rand.NewSource(seed))
+	p := make([]byte, 0, size)
+	for int64(len(p)) < size {
+		n := min(dist.draw(rnd), size-int64(len(p)))
+		zero := rnd.Float64() < zeroDensity
+		if zero {
+			n++
+		}
+		for i := int64(0); i < n; i++ {
+			p = append(p, 0x31+byte(rnd.Intn(2)))
+		}
+		switch {
+		case zero:
+			p = append(p, 0x30)
+		case n > 0 && p[len(p)-1] == 0x32:
+			p = append(p, 0x33+byte(rnd.Intn(4))) // 23 to 26
+		default:
+			p = append(p, 0x33+byte(rnd.Intn(7))) // 13 to 19, or a lone digit
+		}
+	}
+	return p[:size]
+}
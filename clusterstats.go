@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+	"text/tabwriter"
+
+	"task1/decodeways"
+)
+
+// clusterStats is the report of --cluster-stats, a fingerprint of a dataset.
+type clusterStats struct {
+	clusters int
+	digits   int // Digits in clusters
+	largest  decodeways.Cluster
+	sizes    map[uint64]int // Number of clusters by bucket, see sizeBucket
+	zeros    map[string]int // Occurrences of the codes ending in a zero, e.g. "10"
+	gaps     int            // Number of gaps between consecutive clusters
+	gapSum   int
+	gapMin   int
+	gapMax   int
+}
+
+// sizeBucket returns the histogram bucket of a cluster size: sizes up to 8
+// have their own bucket, larger ones share one per power of two (9-16,
+// 17-32, ...), identified by its upper bound.
+func sizeBucket(n uint64) uint64 {
+	if n <= 8 {
+		return n
+	}
+	return 1 << bits.Len64(n-1)
+}
+
+// collectClusterStats scans an input for --cluster-stats.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - s: The letter mapping scheme
+//
+// Returns:
+//   - *clusterStats: The statistics
+//   - error: An error if the input is invalid
+func collectClusterStats(p []byte, s *decodeways.Scheme) (*clusterStats, error) {
+	st := &clusterStats{sizes: map[uint64]int{}, zeros: map[string]int{}}
+	prevEnd := -1
+	err := decodeways.EachCluster(p, s, func(cl decodeways.Cluster) {
+		st.clusters++
+		st.digits += cl.End - cl.Start
+		st.sizes[sizeBucket(cl.Size)]++
+		if cl.Size > st.largest.Size {
+			st.largest = cl
+		}
+		if prevEnd >= 0 {
+			gap := cl.Start - prevEnd
+			if st.gaps == 0 || gap < st.gapMin {
+				st.gapMin = gap
+			}
+			st.gapMax = max(st.gapMax, gap)
+			st.gaps++
+			st.gapSum += gap
+		}
+		prevEnd = cl.End
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(p); i++ {
+		if !s.Single.Has(p[i] - 0x30) { // Valid, so attached to p[i-1]
+			st.zeros[string(p[i-1:i+1])]++
+		}
+	}
+	return st, nil
+}
+
+// write writes the report: the number of clusters and the digits they
+// cover, the largest cluster and where it is, the occurrences of the codes
+// with a zero, the gaps between clusters and a histogram of cluster sizes.
+//
+// Parameters:
+//   - w: The writer
+//   - n: Length of the input
+func (st *clusterStats) write(w io.Writer, n int) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "clusters\t%d\n", st.clusters)
+	fmt.Fprintf(tw, "digits in clusters\t%d (%.1f%%)\n", st.digits, 100*float64(st.digits)/float64(n))
+	if st.clusters > 0 {
+		fmt.Fprintf(tw, "largest cluster\t%d pairs at %d-%d\n", st.largest.Size, st.largest.Start, st.largest.End)
+	}
+	codes := make([]string, 0, len(st.zeros))
+	for c := range st.zeros {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	for _, c := range codes {
+		fmt.Fprintf(tw, "code %s\t%d\n", c, st.zeros[c])
+	}
+	if st.gaps > 0 {
+		fmt.Fprintf(tw, "gaps\tmin %d, mean %.1f, max %d digits\n", st.gapMin, float64(st.gapSum)/float64(st.gaps), st.gapMax)
+	}
+	tw.Flush()
+
+	if st.clusters == 0 {
+		return
+	}
+	buckets := make([]uint64, 0, len(st.sizes))
+	for b := range st.sizes {
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "pairs\tclusters\t")
+	for _, b := range buckets {
+		label := fmt.Sprint(b)
+		if b > 8 {
+			label = fmt.Sprintf("%d-%d", b/2+1, b)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t\n", label, st.sizes[b])
+	}
+	tw.Flush()
+}
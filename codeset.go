@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// codeTable is an arbitrary code set read from a configuration file.
+type codeTable struct {
+	symbols map[string]string // Code -> symbol
+	w       int               // Length of the longest code
+}
+
+// loadCodeTable reads a code set from a JSON file.
+//
+// The file holds an object mapping symbols to their digit codes, e.g.
+// {"A": "1", "B": "2", ..., "Z": "26"}. Codes can have any length, but must
+// be non-empty, consist of digits only and be assigned to a single symbol.
+//
+// Parameters:
+//   - filename: Path to the JSON file
+//
+// Returns:
+//   - *codeTable: The code set
+//   - error: An error if the file can not be read or is not a valid code set
+func loadCodeTable(filename string) (*codeTable, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, errors.New("code set is empty")
+	}
+
+	t := &codeTable{symbols: make(map[string]string, len(m))}
+	for sym, code := range m {
+		if code == "" {
+			return nil, fmt.Errorf("symbol %q has an empty code", sym)
+		}
+		for i := 0; i < len(code); i++ {
+			if code[i] < 0x30 || code[i] > 0x39 {
+				return nil, fmt.Errorf("code %q of symbol %q is not a digit string", code, sym)
+			}
+		}
+		if other, ok := t.symbols[code]; ok {
+			return nil, fmt.Errorf("code %q is assigned to both %q and %q", code, other, sym)
+		}
+		t.symbols[code] = sym
+		if len(code) > t.w {
+			t.w = len(code)
+		}
+	}
+	return t, nil
+}
+
+// valid reports whether a digit string is a code of the set.
+func (t *codeTable) valid(c []byte) bool {
+	_, ok := t.symbols[string(c)]
+	return ok
+}
+
+// width returns the length of the longest code.
+func (t *codeTable) width() int {
+	return t.w
+}
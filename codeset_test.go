@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCodes writes a code set to a JSON file in a temporary directory.
+func writeCodes(t *testing.T, json string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "codes.json")
+	if err := os.WriteFile(name, []byte(json), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestLoadCodeTable(t *testing.T) {
+	tbl, err := loadCodeTable(writeCodes(t, `{"A": "1", "B": "01", "C": "101", "D": "0"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl.width() != 3 {
+		t.Errorf("width() = %d, want 3", tbl.width())
+	}
+	for _, c := range []string{"1", "01", "101", "0"} {
+		if !tbl.valid([]byte(c)) {
+			t.Errorf("valid(%q) = false, want true", c)
+		}
+	}
+	for _, c := range []string{"10", "2", "", "0101"} {
+		if tbl.valid([]byte(c)) {
+			t.Errorf("valid(%q) = true, want false", c)
+		}
+	}
+
+	for _, json := range []string{
+		`{}`,
+		`{"A": ""}`,
+		`{"A": "1a"}`,
+		`{"A": "1", "B": "1"}`,
+		`["1", "2"]`,
+		`{"A": 1}`,
+	} {
+		if _, err := loadCodeTable(writeCodes(t, json)); err == nil {
+			t.Errorf("loadCodeTable accepts %s", json)
+		}
+	}
+	if _, err := loadCodeTable(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loadCodeTable accepts a missing file")
+	}
+}
+
+func TestCountCodeTable(t *testing.T) {
+	tests := []struct {
+		codes string
+		in    string
+		want  string
+	}{
+		{`{"A": "1", "B": "01", "C": "101", "D": "0"}`, "1010", "3"}, // 1|0|1|0, 1|01|0, 101|0
+		{`{"A": "1", "B": "11", "C": "111"}`, "1111", "7"},           // Tribonacci
+		{`{"A": "12", "B": "123", "C": "3"}`, "123", "2"},
+		{`{"A": "12"}`, "121", ""},
+	}
+	for _, tt := range tests {
+		tbl, err := loadCodeTable(writeCodes(t, tt.codes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := countWindow([]byte(tt.in), tbl)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("countWindow(%q) under %s = %v, want an error", tt.in, tt.codes, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("countWindow(%q) under %s = %v, %v, want %s", tt.in, tt.codes, got, err, tt.want)
+		}
+		if want := tokenizations([]byte(tt.in), tbl); got.Cmp(want) != 0 {
+			t.Errorf("countWindow(%q) under %s = %v, but the plain DP counts %v", tt.in, tt.codes, got, want)
+		}
+	}
+
+	// The code set of the standard alphabet counts like the default
+	standard := `{"A": "1", "B": "2", "C": "3", "D": "4", "E": "5", "F": "6", "G": "7", "H": "8", "I": "9",
+		"J": "10", "K": "11", "L": "12", "M": "13", "N": "14", "O": "15", "P": "16", "Q": "17", "R": "18", "S": "19",
+		"T": "20", "U": "21", "V": "22", "W": "23", "X": "24", "Y": "25", "Z": "26"}`
+	c, err := newCounter(countOptions{alphabet: 26, codes: writeCodes(t, standard), threads: 1, zeros: zerosError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := newCounter(countOptions{alphabet: 26, threads: 1, zeros: zerosError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range []string{"226", "11106", "1201234", "2611055971756562"} {
+		x, err := c.countInt([]byte(in))
+		y, err2 := d.countInt([]byte(in))
+		if err != nil || err2 != nil || x.Cmp(y) != 0 {
+			t.Errorf("the standard code set counts %v, %v decodings of %q, want %v, %v", x, err, in, y, err2)
+		}
+	}
+}
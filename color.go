@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// ANSI colors of diagnostics. All codes have the same length, so columns of
+// colored cells stay aligned in a tabwriter.
+const (
+	ansiBold   = "\x1b[01m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiPlain  = "\x1b[39m" // Default color
+	ansiReset  = "\x1b[0m"
+)
+
+// colorStdout and colorStderr enable colors on stdout and stderr, see
+// setupColor.
+var colorStdout, colorStderr bool
+
+// isTerminal reports whether a file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// setupColor enables colors on stdout and stderr where they are terminals,
+// unless disabled by --no-color or the NO_COLOR environment variable (see
+// https://no-color.org).
+//
+// Parameters:
+//   - disabled: Whether --no-color is given
+func setupColor(disabled bool) {
+	if disabled || os.Getenv("NO_COLOR") != "" {
+		colorStdout, colorStderr = false, false
+		return
+	}
+	colorStdout, colorStderr = isTerminal(os.Stdout), isTerminal(os.Stderr)
+}
+
+// paint colors a string if on is set.
+func paint(on bool, color, s string) string {
+	if !on || s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// diagnostic colors a message for stderr, if colors are enabled there: the
+// label of the first line up to its colon ("Error decoding:" in red,
+// "Warning:" in yellow) and the carets of snippets (see snippet) in red, with
+// the characters they point at.
+//
+// Parameters:
+//   - s: The message
+//
+// Returns:
+//   - string: The colored message
+func diagnostic(s string) string {
+	if !colorStderr {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	if i := strings.IndexByte(lines[0], ':'); i > 0 {
+		color := ansiRed
+		if strings.HasPrefix(lines[0], "Warning") {
+			color = ansiYellow
+		}
+		lines[0] = paint(true, color, lines[0][:i+1]) + lines[0][i+1:]
+	}
+	for k := 1; k < len(lines); k++ {
+		t := strings.TrimLeft(lines[k], " ")
+		if t == "" || strings.Trim(t, "^") != "" {
+			continue
+		}
+		col := len(lines[k]) - len(t)
+		lines[k] = lines[k][:col] + paint(true, ansiRed, t)
+		if prev := lines[k-1]; col+len(t) <= len(prev) {
+			lines[k-1] = prev[:col] + paint(true, ansiRed, prev[col:col+len(t)]) + prev[col+len(t):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// comparison is the comparison of the counts of two inputs: both counts,
+// their ratio as a reduced fraction and the difference in the number of
+// decimal digits of the counts.
+type comparison struct {
+	A          string `json:"a"`
+	B          string `json:"b"`
+	CountA     string `json:"count_a"`
+	CountB     string `json:"count_b"`
+	Ratio      string `json:"ratio,omitempty"` // Empty if the count of B is 0
+	DigitsA    int    `json:"digits_a"`
+	DigitsB    int    `json:"digits_b"`
+	DigitsDiff int    `json:"digits_diff"`
+}
+
+// compareCounts compares the counts of two inputs.
+//
+// Parameters:
+//   - names: The names of the inputs
+//   - a, b: Their counts
+//
+// Returns:
+//   - comparison: The comparison
+func compareCounts(names [2]string, a, b *big.Int) comparison {
+	r := comparison{A: names[0], B: names[1], CountA: a.String(), CountB: b.String()}
+	if b.Sign() != 0 {
+		r.Ratio = new(big.Rat).SetFrac(a, b).RatString()
+	}
+	r.DigitsA, r.DigitsB = len(r.CountA), len(r.CountB)
+	r.DigitsDiff = r.DigitsA - r.DigitsB
+	return r
+}
+
+// plain implements plainRecord.
+func (r comparison) plain() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", r.A, r.CountA)
+	fmt.Fprintf(&b, "%s: %s\n", r.B, r.CountB)
+	if r.Ratio == "" {
+		b.WriteString("ratio: undefined, the second input has no decodings\n")
+	} else {
+		fmt.Fprintf(&b, "ratio: %s\n", r.Ratio)
+	}
+	fmt.Fprintf(&b, "count digits: %d vs %d (%+d)\n", r.DigitsA, r.DigitsB, r.DigitsDiff)
+	return b.String()
+}
+
+// runCompare implements the "compare" subcommand: it counts two inputs under
+// the same count options and compares the counts (see writeComparison).
+//
+// Usage: decode-ways compare [flags] <a> <b>
+//
+// Returns:
+//   - int: The process exit code
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	countOpts := addCountFlags(fs)
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways compare [flags] <a> <b>")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways compare before.txt after.txt")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+	if countOpts.mod != 0 || countOpts.atLeast != "" {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: compare needs exact counts, not --mod or --at-least\n")))
+		return 1
+	}
+	c, err := newCounter(*countOpts)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+
+	var x [2]*big.Int
+	for i, name := range fs.Args() {
+		r, err := countFile(c, name, nil)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(fmt.Sprintf("Error decoding %s: %v\n", name, trError(err))))
+			return exitCode(err)
+		}
+		x[i], _ = new(big.Int).SetString(r, 10)
+	}
+	return formatAll(f, compareCounts([2]string{fs.Arg(0), fs.Arg(1)}, x[0], x[1]))
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// bashCompletion is the bash completion script. %[1]s is the list of
+// subcommands. Flags are completed by asking the binary itself (see
+// runFlags), so the script never goes out of date.
+const bashCompletion = `# bash completion for decode-ways
+# Usage: source <(decode-ways completion bash)
+_decode_ways() {
+	local cur=${COMP_WORDS[COMP_CWORD]} cmd=count c
+	for c in %[1]s; do
+		[[ $COMP_CWORD -gt 1 && ${COMP_WORDS[1]} == "$c" ]] && cmd=$c
+	done
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$("${COMP_WORDS[0]}" __flags "$cmd" 2>/dev/null)" -- "$cur"))
+		return
+	fi
+	COMPREPLY=()
+	[[ $COMP_CWORD -eq 1 ]] && COMPREPLY=($(compgen -W "%[1]s" -- "$cur"))
+	COMPREPLY+=($(compgen -f -- "$cur"))
+}
+complete -o filenames -F _decode_ways decode-ways
+`
+
+// zshCompletion is the zsh completion script. %[1]s is the list of
+// subcommands, %[2]s the same with their descriptions.
+const zshCompletion = `#compdef decode-ways
+# Usage: source <(decode-ways completion zsh)
+_decode_ways() {
+	local cmd=count
+	local -a cmds=(%[1]s) described=(%[2]s) flags
+	if (( CURRENT > 2 && ${cmds[(Ie)$words[2]]} )); then
+		cmd=$words[2]
+	fi
+	if [[ $PREFIX == -* ]]; then
+		flags=(${(f)"$($words[1] __flags $cmd 2>/dev/null)"})
+		compadd -a flags
+	elif (( CURRENT == 2 )); then
+		_describe 'command' described
+		_files
+	else
+		_files
+	fi
+}
+compdef _decode_ways decode-ways
+`
+
+// fishCompletion is the fish completion script. %[1]s is the list of
+// subcommands, %[2]s the lines completing each of them.
+const fishCompletion = `# fish completion for decode-ways
+# Usage: decode-ways completion fish | source
+function __decode_ways_flags
+	set -l tokens (commandline -opc)
+	set -l cmd count
+	if contains -- "$tokens[2]" %[1]s
+		set cmd $tokens[2]
+	end
+	$tokens[1] __flags $cmd 2>/dev/null
+end
+%[2]scomplete -c decode-ways -n 'string match -q -- "-*" (commandline -ct)' -f -a '(__decode_ways_flags)'
+`
+
+// runCompletion implements the "completion" subcommand, which prints the
+// completion script of a shell.
+//
+// Usage: decode-ways completion bash|zsh|fish
+//
+// Returns:
+//   - int: The process exit code
+func runCompletion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways completion bash|zsh|fish")
+		fmt.Fprintln(os.Stderr, "Example: source <(decode-ways completion bash)")
+		return 1
+	}
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	list := strings.Join(names, " ")
+
+	switch args[0] {
+	case "bash":
+		fmt.Printf(bashCompletion, list)
+	case "zsh":
+		described := make([]string, len(commands))
+		for i, cmd := range commands {
+			described[i] = fmt.Sprintf("'%s:%s'", cmd.name, cmd.summary)
+		}
+		fmt.Printf(zshCompletion, list, strings.Join(described, " "))
+	case "fish":
+		var b strings.Builder
+		for _, cmd := range commands {
+			fmt.Fprintf(&b, "complete -c decode-ways -n __fish_use_subcommand -a %s -d '%s'\n", cmd.name, cmd.summary)
+		}
+		fmt.Printf(fishCompletion, list, b.String())
+	default:
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: unknown shell %q\n", args[0])))
+		return 1
+	}
+	return 0
+}
+
+// runFlags implements the hidden "__flags" command the completion scripts
+// use: it prints the flags of a subcommand, one per line.
+//
+// Returns:
+//   - int: The process exit code
+func runFlags(args []string) int {
+	if len(args) != 1 || findCommand(args[0]) == nil {
+		return 1
+	}
+	listFlags = true
+	return findCommand(args[0]).run(nil) // parseFlags prints the flags and exits
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressMinSize is the size of the first write of a response below which
+// it is sent uncompressed, as compressing would not pay for its headers.
+const compressMinSize = 1024
+
+// encoder is a compressing writer that can be reused for another response.
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// encoderPools hold the encoders of the supported content codings.
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() any { return gzip.NewWriter(nil) }},
+	"zstd": {New: func() any {
+		e, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(err) // The options are constant
+		}
+		return e
+	}},
+}
+
+// parseCodings parses the --compress list of content codings.
+//
+// Parameters:
+//   - s: Comma-separated codings in the order of preference, "" for none
+//
+// Returns:
+//   - []string: The codings, nil if compression is disabled
+//   - error: An error if a coding is not supported
+func parseCodings(s string) ([]string, error) {
+	var codings []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if encoderPools[c] == nil {
+			return nil, fmt.Errorf("unsupported coding %q (gzip or zstd)", c)
+		}
+		codings = append(codings, c)
+	}
+	return codings, nil
+}
+
+// negotiateCoding picks the content coding of a response from the
+// Accept-Encoding header of the request: the one with the highest q-value
+// among the codings of the server, the earliest in its order on a tie.
+//
+// Parameters:
+//   - accept: The Accept-Encoding header
+//   - codings: The codings of the server in the order of preference
+//
+// Returns:
+//   - string: The coding, "" to send the response uncompressed
+func negotiateCoding(accept string, codings []string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[name] = weight
+	}
+	best, bestQ := "", 0.0
+	for _, c := range codings {
+		w, ok := q[c]
+		if !ok {
+			w, ok = q["*"]
+		}
+		if ok && w > bestQ {
+			best, bestQ = c, w
+		}
+	}
+	return best
+}
+
+// compressWriter compresses a response with the negotiated coding. The
+// choice is made at the first write, when the headers are final: responses
+// whose first write is short, or that are already encoded, are sent as they
+// are.
+type compressWriter struct {
+	http.ResponseWriter
+	coding  string
+	enc     encoder // nil until the first write, or if not compressed
+	status  int     // Status of WriteHeader, sent with the first write
+	decided bool
+}
+
+// WriteHeader implements http.ResponseWriter. The status is held back until
+// the first write, which decides on the Content-Encoding header.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decide(len(p))
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide sends the headers, compressed or not by the size of the first write.
+func (w *compressWriter) decide(n int) {
+	w.decided = true
+	h := w.Header()
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if n >= compressMinSize && h.Get("Content-Encoding") == "" && w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		h.Set("Content-Encoding", w.coding)
+		h.Del("Content-Length")
+		w.enc = encoderPools[w.coding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// Flush implements http.Flusher, for responses streamed in parts.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(0)
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close ends the compressed stream and returns the encoder to its pool.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 {
+			return // Nothing written, net/http sends the default response
+		}
+		w.decide(0)
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(nil)
+		encoderPools[w.coding].Put(w.enc)
+	}
+}
+
+// compress compresses the responses of the HTTP endpoints with the coding
+// negotiated by Accept-Encoding (see negotiateCoding), as enumerations and
+// huge counts compress well. WebSocket upgrades are passed through.
+func (sv *server) compress(h http.Handler) http.Handler {
+	if sv.codings == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		coding := negotiateCoding(r.Header.Get("Accept-Encoding"), sv.codings)
+		if coding == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, coding: coding}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// errBusy is the error of a count refused because all compute workers are
+// taken and the compute queue is full.
+var errBusy = errors.New("server busy, all compute workers taken and the compute queue full")
+
+// computePool caps the number of counts running at once (--compute-workers),
+// whatever the number of connections: counts of huge inputs hold hundreds of
+// megabytes of big.Int each, so letting every request count at once runs the
+// server out of memory under load. Counts over the cap wait in a queue of
+// bounded length (--compute-queue); requests beyond it are refused, so a
+// flood is turned away rather than piling up. A nil pool lets every count
+// run at once.
+type computePool struct {
+	slots   chan struct{} // A token per running count
+	queue   int64         // Maximum number of waiting counts
+	waiting atomic.Int64
+}
+
+// newComputePool returns a pool of workers compute workers with a queue of
+// up to queue waiting counts, or nil if workers is not positive.
+func newComputePool(workers, queue int) *computePool {
+	if workers <= 0 {
+		return nil
+	}
+	return &computePool{slots: make(chan struct{}, workers), queue: int64(queue)}
+}
+
+// acquire takes a compute worker, waiting in the queue if all are taken.
+//
+// Parameters:
+//   - ctx: The context of the count; waiting ends when it is done
+//   - refuse: Whether to fail with errBusy if the queue is full rather than
+//     wait anyway, as jobs do, which have a queue of their own
+//
+// Returns:
+//   - func(): Releases the worker
+//   - error: errBusy, or ctx.Err() if ctx was done first
+func (cp *computePool) acquire(ctx context.Context, refuse bool) (func(), error) {
+	if cp == nil {
+		return func() {}, nil
+	}
+	release := func() {
+		<-cp.slots
+		metricComputeRunning.Add(-1)
+	}
+	select {
+	case cp.slots <- struct{}{}:
+		metricComputeRunning.Add(1)
+		return release, nil
+	default:
+	}
+	if n := cp.waiting.Add(1); refuse && n > cp.queue {
+		cp.waiting.Add(-1)
+		metricComputeRejected.Add(1)
+		return nil, errBusy
+	}
+	metricComputeWaiting.Add(1)
+	defer func() {
+		cp.waiting.Add(-1)
+		metricComputeWaiting.Add(-1)
+	}()
+	select {
+	case cp.slots <- struct{}{}:
+		metricComputeRunning.Add(1)
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
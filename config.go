@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// configEnv names the environment variable with the path of the config file,
+// overriding the default one (see configPath).
+const configEnv = "DECODE_WAYS_CONFIG"
+
+// configPath returns the path of the config file: $DECODE_WAYS_CONFIG, or
+// decode-ways/config.toml in the user config directory ($XDG_CONFIG_HOME or
+// ~/.config on Linux), or an empty string if there is none.
+func configPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "decode-ways", "config.toml")
+}
+
+// applyConfig sets the defaults of the flags of a subcommand from the config
+// file, before the command line is parsed, so flags given there override
+// them. A missing config file is no error.
+//
+// The keys are the names of flags. Top-level keys apply to every subcommand
+// with such a flag, keys of a table named after a subcommand only to it and
+// override the top-level ones:
+//
+//	format = "json"
+//	max-len = "10M"
+//
+//	[count]
+//	workers = 4
+//	alphabet = 30
+//
+//	[bench]
+//	sizes = ["1M", "100M"]   # Lists are joined with commas
+//
+// Parameters:
+//   - flags: The flag set of the subcommand, not parsed yet
+//
+// Returns:
+//   - error: An error if the config file is invalid, or a table of the
+//     subcommand has a key that is not one of its flags
+func applyConfig(flags *flag.FlagSet) error {
+	path := configPath()
+	if path == "" {
+		return nil
+	}
+	var cfg map[string]any
+	if _, err := toml.DecodeFile(path, &cfg); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("config '%s': %w", path, err)
+	}
+
+	set := func(name string, v any) error {
+		var s string
+		switch v := v.(type) {
+		case []any:
+			parts := make([]string, len(v))
+			for i, x := range v {
+				parts[i] = fmt.Sprint(x)
+			}
+			s = strings.Join(parts, ",")
+		default:
+			s = fmt.Sprint(v)
+		}
+		if err := flags.Set(name, s); err != nil {
+			return fmt.Errorf("config '%s': %s = %q: %w", path, name, s, err)
+		}
+		return nil
+	}
+	for _, k := range sortedKeys(cfg) {
+		if _, table := cfg[k].(map[string]any); !table && flags.Lookup(k) != nil {
+			if err := set(k, cfg[k]); err != nil {
+				return err
+			}
+		}
+	}
+	if t, ok := cfg[flags.Name()].(map[string]any); ok {
+		for _, k := range sortedKeys(t) {
+			if flags.Lookup(k) == nil {
+				return fmt.Errorf("config '%s': [%s]: unknown flag %q", path, flags.Name(), k)
+			}
+			if err := set(k, t[k]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a table in order, so the flags are set
+// deterministically.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
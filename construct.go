@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"task1/decodeways"
+)
+
+// constructInput builds a short digit string whose number of decode ways is n.
+//
+// The number of decode ways is always a product of Fibonacci numbers, one
+// F(k) per cluster (see getPossibleCombinations), so n is factored into
+// Fibonacci numbers F(k), k >= 3, minimizing the total length. Every factor
+// F(k) is emitted as k-2 '2's followed by a '6': all pairs inside are valid
+// codes (22, 26) while "62" is not, so the clusters can be simply concatenated.
+//
+// Parameters:
+//   - n: The requested number of decode ways (positive)
+//
+// Returns:
+//   - []byte: A digit string with exactly n decodings
+//   - error: An error if n is not positive or is not a product of Fibonacci numbers
+func constructInput(n *big.Int) ([]byte, error) {
+	if n.Sign() <= 0 {
+		return nil, errors.New("count must be positive")
+	}
+	if n.Cmp(big.NewInt(1)) == 0 {
+		return []byte("1"), nil
+	}
+
+	// Fibonacci numbers F(3), F(4), ... not exceeding n
+	var ks []uint64
+	for k := uint64(3); decodeways.Fib(k).Cmp(n) <= 0; k++ {
+		ks = append(ks, k)
+	}
+
+	plan := factorFibonacci(n, ks, map[string][]uint64{"1": {}})
+	if plan == nil {
+		return nil, fmt.Errorf("%v is not a product of Fibonacci numbers, so no input has that many decodings", n)
+	}
+
+	var out bytes.Buffer
+	sort.Slice(plan, func(i, j int) bool { return plan[i] > plan[j] })
+	for _, k := range plan {
+		out.Write(bytes.Repeat([]byte{'2'}, int(k-2)))
+		out.WriteByte('6')
+	}
+	return out.Bytes(), nil
+}
+
+// factorFibonacci finds the cheapest factorization of n into Fibonacci numbers.
+//
+// A factor F(k) costs k-1 digits. The search tries every Fibonacci divisor
+// and memoizes the best plan for each remaining quotient.
+//
+// Parameters:
+//   - n: The number to factor
+//   - ks: Candidate Fibonacci indexes
+//   - memo: Best plans found so far, keyed by decimal quotient (nil if impossible)
+//
+// Returns:
+//   - []uint64: Fibonacci indexes of the factors, or nil if there is no factorization
+func factorFibonacci(n *big.Int, ks []uint64, memo map[string][]uint64) []uint64 {
+	key := n.String()
+	if plan, ok := memo[key]; ok {
+		return plan
+	}
+
+	var best []uint64
+	bestCost := uint64(0)
+	q, r := new(big.Int), new(big.Int)
+	for i := len(ks) - 1; i >= 0; i-- {
+		k := ks[i]
+		if decodeways.Fib(k).Cmp(n) > 0 {
+			continue
+		}
+		if q.QuoRem(n, decodeways.Fib(k), r); r.Sign() != 0 {
+			continue
+		}
+		sub := factorFibonacci(new(big.Int).Set(q), ks[:i+1], memo)
+		if sub == nil {
+			continue
+		}
+		cost := k - 1
+		for _, s := range sub {
+			cost += s - 1
+		}
+		if best == nil || cost < bestCost {
+			best = append(append([]uint64{}, sub...), k)
+			bestCost = cost
+		}
+	}
+	memo[key] = best
+	return best
+}
+
+// runConstruct implements the "construct" subcommand.
+//
+// Usage: decode-ways construct [--format json] --count <n>
+//
+// Returns:
+//   - int: The process exit code
+func runConstruct(args []string) int {
+	fs := flag.NewFlagSet("construct", flag.ExitOnError)
+	count := fs.String("count", "", "requested number of decode ways")
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways construct [--format json] --count <n>")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways construct --count 1000000")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if *count == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+
+	n, ok := new(big.Int).SetString(*count, 10)
+	if !ok {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: invalid count %q\n", *count)))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	p, err := constructInput(n)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error constructing: %v\n", trError(err))))
+		return 1
+	}
+	return formatAll(f, constructRecord{Count: n.String(), Input: string(p)})
+}
+
+// constructRecord is the record of an input built by construct.
+type constructRecord struct {
+	Count string `json:"count"`
+	Input string `json:"input"`
+}
+
+// plain implements plainRecord: the input alone, without a newline, so it
+// can be redirected into a file as is.
+func (r constructRecord) plain() string {
+	return r.Input
+}
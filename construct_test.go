@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"task1/decodeways"
+)
+
+func TestConstructInput(t *testing.T) {
+	tests := []struct {
+		n       string
+		wantLen int // Length of the shortest input, 0 if not checked
+	}{
+		{"1", 1},
+		{"2", 2}, // F(3) = 2: "26"
+		{"3", 3},
+		{"4", 4}, // 2 * 2
+		{"5", 4},
+		{"6", 5}, // 2 * 3
+		{"8", 5},
+		{"13", 6},
+		{"1000000", 0},
+		{"354224848179261915075", 99}, // F(100)
+	}
+	for _, tt := range tests {
+		n, _ := new(big.Int).SetString(tt.n, 10)
+		p, err := constructInput(n)
+		if err != nil {
+			t.Errorf("constructInput(%s): %v", tt.n, err)
+			continue
+		}
+		got, err := getPossibleCombinations(p, &decodeways.StandardScheme)
+		if err != nil || got.Cmp(n) != 0 {
+			t.Errorf("constructInput(%s) = %q with %v decodings (%v)", tt.n, p, got, err)
+		}
+		if tt.wantLen != 0 && len(p) != tt.wantLen {
+			t.Errorf("constructInput(%s) = %q, want %d digits", tt.n, p, tt.wantLen)
+		}
+	}
+
+	// Every count up to 100 that is a product of Fibonacci numbers round-trips,
+	// and the others are refused
+	for i := int64(1); i <= 100; i++ {
+		n := big.NewInt(i)
+		p, err := constructInput(n)
+		if err != nil {
+			continue
+		}
+		if got, err := getPossibleCombinations(p, &decodeways.StandardScheme); err != nil || got.Cmp(n) != 0 {
+			t.Errorf("constructInput(%d) = %q with %v decodings (%v)", i, p, got, err)
+		}
+	}
+	for _, n := range []int64{7, 11, 0, -3} {
+		if p, err := constructInput(big.NewInt(n)); err == nil {
+			t.Errorf("constructInput(%d) = %q, want an error", n, p)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"runtime"
+	"time"
+
+	"task1/decodeways"
+)
+
+// countOptions are the command-line options of the count mode.
+type countOptions struct {
+	alphabet  int    // Number of letters, 26 by default
+	zeroBased bool   // Whether the first letter is 0 rather than 1
+	codes     string // JSON file with a custom code set, empty for none
+	mod       uint64 // Modulus, 0 for the exact count
+	threads   int    // Number of workers scanning a large input
+	atLeast   string // Threshold of --at-least, empty for the exact count
+	verify    bool   // Whether to check counts against the classic DP
+	allErrors bool   // Whether to report every invalid position
+	maxErrors int    // Maximum number of invalid positions reported
+	lenient   bool   // Whether to skip non-digit characters
+	zeros     string // Policy for zeros that can not be attached, see zeroPolicies
+	maxLen    string // Maximum length of an input (--max-len), empty for none
+}
+
+// addCountFlags registers the flags of the count options.
+//
+// Parameters:
+//   - fs: The flag set, e.g. flag.CommandLine
+//
+// Returns:
+//   - *countOptions: The options, filled in when fs is parsed
+func addCountFlags(fs *flag.FlagSet) *countOptions {
+	o := &countOptions{}
+	fs.BoolVar(&o.zeroBased, "zero-based", false, "use the mapping 'A' -> 0, ..., 'Z' -> 25 when counting")
+	fs.IntVar(&o.alphabet, "alphabet", 26, "number of `letters` in the alphabet when counting")
+	fs.StringVar(&o.codes, "codes", "", "count tokenizations under the code set in a JSON `file` mapping symbols to codes")
+	fs.IntVar(&o.threads, "threads", runtime.NumCPU(), "number of `workers` scanning a large input in parallel")
+	fs.Uint64Var(&o.mod, "mod", 0, "print the count modulo `m` (0 < m < 2^63) using constant memory")
+	fs.BoolVar(&o.verify, "verify", false, "check every count against the classic DP modulo a random 64-bit prime")
+	fs.BoolVar(&o.allErrors, "all-errors", false, "report every invalid position of an invalid input, not only the first")
+	fs.IntVar(&o.maxErrors, "max-errors", 100, "report at most `n` invalid positions with --all-errors")
+	fs.BoolVar(&o.lenient, "lenient", false, "skip non-digit characters as boundaries between runs of digits, with a warning")
+	fs.StringVar(&o.zeros, "zeros", zerosError, "`policy` for a '0' that can not be attached: error, zero (the count is 0) or split (a boundary)")
+	fs.StringVar(&o.maxLen, "max-len", "", "refuse inputs longer than `size` (e.g. 10M) before reading them")
+	fs.StringVar(&o.atLeast, "at-least", "", "print \"≥ k\" as soon as the count provably reaches `k`, or the count if it is below")
+	return o
+}
+
+// counter counts inputs under fixed options. It is safe for concurrent use,
+// as serve and --workers count with it from several goroutines: the counts
+// only share the Fibonacci numbers of decodeways.Fib and its disk cache, which
+// are synchronized.
+type counter struct {
+	s         *decodeways.Scheme
+	count     func(p []byte) (*big.Int, error) // Count of an input without wildcards
+	wide      bool                             // Whether codes can be longer than two digits
+	codes     codeSet                          // Codes of a wide counter
+	threads   int
+	mod       uint64
+	atLeast   *big.Int // Threshold of --at-least, nil for the exact count
+	verify    bool     // Whether to check counts of the cluster scan against the DP
+	maxErrors int      // Invalid positions reported at most, 0 for only the first
+	lenient   bool     // Whether to skip non-digit characters
+	zeros     string   // Policy for zeros that can not be attached
+	maxLen    int64    // Maximum length of an input in bytes, 0 for none
+	options   string   // Options affecting the result, part of the result cache key
+}
+
+// newCounter selects the counting algorithm for the given options.
+//
+// Parameters:
+//   - o: The count options
+//
+// Returns:
+//   - *counter: The counter
+//   - error: An error if the alphabet is invalid or the code set can not be loaded
+func newCounter(o countOptions) (*counter, error) {
+	c := &counter{s: &decodeways.StandardScheme, mod: o.mod, threads: o.threads, verify: o.verify, lenient: o.lenient, zeros: o.zeros}
+	if o.allErrors {
+		if o.maxErrors <= 0 {
+			return nil, fmt.Errorf("--max-errors: invalid number %d", o.maxErrors)
+		}
+		c.maxErrors = o.maxErrors
+	}
+	c.count = func(p []byte) (*big.Int, error) { return countParallel(p, c.s, o.threads) }
+	if o.zeroBased || o.alphabet != 26 {
+		r, err := newCodeRange(o.alphabet, o.zeroBased)
+		if err != nil {
+			return nil, err
+		}
+		if r.width() > 2 {
+			c.wide, c.codes = true, r
+			c.count = func(p []byte) (*big.Int, error) { return countWindow(p, r) }
+		} else {
+			sc := r.scheme()
+			c.s = &sc
+		}
+	}
+
+	// Unchanged inputs are answered from the result cache; the key covers
+	// every option that affects the count, including the code set itself
+	c.options = fmt.Sprintf("alphabet=%d zero-based=%t mod=%d", o.alphabet, o.zeroBased, o.mod)
+	if o.lenient {
+		c.options += " lenient"
+	}
+	if !zeroPolicies[o.zeros] {
+		return nil, fmt.Errorf("--zeros: unknown policy %q", o.zeros)
+	} else if o.zeros != zerosError {
+		c.options += " zeros=" + o.zeros
+	}
+	if o.maxLen != "" {
+		n, err := parseSize(o.maxLen)
+		if err != nil {
+			return nil, fmt.Errorf("--max-len: %v", err)
+		}
+		c.maxLen = n
+	}
+	if o.atLeast != "" {
+		k, ok := new(big.Int).SetString(o.atLeast, 10)
+		if !ok || k.Sign() < 0 {
+			return nil, fmt.Errorf("--at-least: invalid threshold %q", o.atLeast)
+		}
+		if o.mod != 0 || o.verify {
+			return nil, errors.New("--at-least can not be combined with --mod or --verify")
+		}
+		c.atLeast = k
+		c.options += " at-least=" + k.String()
+	}
+	if o.codes != "" {
+		t, err := loadCodeTable(o.codes)
+		if err != nil {
+			return nil, fmt.Errorf("loading code set: %w", err)
+		}
+		c.wide, c.codes = true, t
+		c.count = func(p []byte) (*big.Int, error) { return countWindow(p, t) }
+		data, err := os.ReadFile(o.codes)
+		if err != nil {
+			return nil, fmt.Errorf("loading code set: %w", err)
+		}
+		c.options += fmt.Sprintf(" codes=%x", sha256.Sum256(data))
+	}
+	if c.wide && c.zeros == zerosSplit {
+		return nil, errors.New("--zeros split only supports codes of up to two digits")
+	}
+	return c, nil
+}
+
+// countInput counts the decodings of an input, answering from the result
+// cache if possible.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string, possibly with wildcards
+//
+// Returns:
+//   - string: The count in decimal
+//   - error: An error if the input is invalid
+func (c *counter) countInput(p []byte) (r string, err error) {
+	hit := false
+	defer func() { recordMetrics(int64(len(p)), hit, err) }()
+	if err := checkInputLen(int64(len(p)), c.maxLen); err != nil {
+		return "", err
+	}
+	verify := c.verify && c.algorithm(p) == "cluster-scan" && !c.lenient // Only the cluster scan of a whole input is checked
+	key := resultKey(p, c.options)
+	if r, ok := loadResult(key); ok && !verify {
+		slog.Info("result", "cached", true)
+		hit = true
+		return r, nil
+	}
+	x, err := c.countInt(p)
+	if err != nil {
+		return "", err
+	}
+	if c.atLeast != nil && x.Cmp(c.atLeast) >= 0 {
+		r = "≥ " + c.atLeast.String()
+	} else {
+		start := time.Now()
+		r = x.String()
+		timings.record("format", start)
+	}
+	storeResult(key, r)
+	return r, nil
+}
+
+// countInt counts the decodings of an input, without the result cache and
+// without converting the count to decimal.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string, possibly with wildcards
+//
+// Returns:
+//   - *big.Int: The count, or with --at-least a number of at least the
+//     threshold if the count reaches it
+//   - error: An error if the input is invalid
+func (c *counter) countInt(p []byte) (*big.Int, error) {
+	if err := checkInputLen(int64(len(p)), c.maxLen); err != nil {
+		return nil, err
+	}
+	count, dp := c.count, c.wide          // dp: whether the algorithm is a DP rather than the cluster scan
+	wild := hasWildcards(p) && !c.lenient // --lenient skips '*' and '[' like any non-digit
+	if wild {
+		if c.wide {
+			return nil, errors.New("wildcards only support codes of up to two digits")
+		}
+		count, dp = func(p []byte) (*big.Int, error) { return countWildcard(p, c.s) }, true
+	}
+	if c.mod != 0 {
+		if c.wide {
+			return nil, errors.New("--mod only supports codes of up to two digits")
+		}
+		mod := countMod
+		if wild {
+			mod = countWildcardMod
+		}
+		count, dp = func(p []byte) (*big.Int, error) {
+			v, err := mod(p, c.s, c.mod)
+			return new(big.Int).SetUint64(v), err
+		}, true
+	}
+
+	verify := c.verify && !dp && !c.lenient // Only the cluster scan of a whole input is checked
+	begin := time.Now()                     // Start of the count
+	start := begin                          // Start of the current phase
+	var x *big.Int
+	var err error
+	if c.lenient || c.zeros == zerosSplit {
+		x, err = c.countRuns(p, count, dp)
+	} else if c.atLeast != nil && !dp {
+		x, err = c.countAtLeast(p)
+	} else {
+		x, err = count(p)
+	}
+	if err != nil && c.zeros == zerosZero && digitError(err, p) {
+		x, err, verify = new(big.Int), nil, false // No decoding, as in LeetCode
+	}
+	if err != nil {
+		if c.maxErrors > 0 && !c.wide && !wild && !c.lenient {
+			if l := collectErrors(p, c.s, c.maxErrors); l != nil {
+				return nil, l
+			}
+		}
+		return nil, withLocation(err, p)
+	}
+	if dp {
+		timings.record("dp", start)
+	}
+	if verify {
+		start = time.Now()
+		if err := verifyCount(p, c.s, x); err != nil {
+			return nil, err
+		}
+		timings.record("verify", start)
+	}
+	slog.Info("result", "bits", x.BitLen(), "cached", false, "duration", time.Since(begin))
+	return x, nil
+}
+
+// algorithm names the algorithm counting an input, for the provenance of
+// its result: the cluster scan, or the DP of --mod, of wildcards or of codes
+// longer than two digits.
+func (c *counter) algorithm(p []byte) string {
+	switch {
+	case c.mod != 0:
+		return "modular-dp"
+	case hasWildcards(p) && !c.lenient:
+		return "wildcard-dp"
+	case c.wide:
+		return "window-dp"
+	}
+	return "cluster-scan"
+}
+
+// withLocation adds the location of an invalid character to the error of a
+// count: a snippet of the input around it (see snippet) and, in an input of
+// several lines, the line and column, so it can be found in an editor.
+//
+// Parameters:
+//   - err: The error of a count
+//   - p: The input
+//
+// Returns:
+//   - error: The error, with the location if it is a decodeways.SyntaxError
+func withLocation(err error, p []byte) error {
+	var se *decodeways.SyntaxError
+	if !errors.As(err, &se) || se.Pos >= len(p) {
+		return err
+	}
+	line, col := 0, se.Pos+1
+	if bytes.IndexByte(p, 0x0A) >= 0 {
+		line = bytes.Count(p[:se.Pos], []byte{0x0A}) + 1
+		col = se.Pos - bytes.LastIndexByte(p[:se.Pos], 0x0A) // 1-based
+	}
+	return withSnippet(err, p, se.Pos, line, col)
+}
+
+// countAtLeast counts the decodings of an input with the cluster scan, but
+// stops multiplying the factors as soon as the count provably reaches the
+// threshold of --at-least (see decodeways.Factors.AtLeast).
+//
+// Parameters:
+//   - p: Byte slice containing the digit string, without wildcards
+//
+// Returns:
+//   - *big.Int: The count if it is below the threshold, otherwise the threshold
+//   - error: An error if the input is invalid
+func (c *counter) countAtLeast(p []byte) (*big.Int, error) {
+	fc, err := scanParallel(p, c.s, c.threads)
+	if err != nil {
+		return big.NewInt(0), err
+	}
+	start := time.Now()
+	x, ok := fc.AtLeast(c.atLeast)
+	timings.record("multiply", start)
+	if ok {
+		x = c.atLeast // AtLeast returns no product once the threshold is reached
+	}
+	slog.Info("multiplied", "factors", len(fc), "bits", x.BitLen(), "reached", ok, "duration", time.Since(start))
+	return x, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"task1/decodeways"
+)
+
+// crossCheckMax is the longest input --cross-check enumerates; a string of n
+// digits has at most F(n+1) decodings, about 3.5 million for 32.
+const crossCheckMax = 32
+
+// enumerateDecodings counts the decodings of a string by walking through
+// every one of them, letter by letter, without any of the shortcuts of the
+// count. Invalid strings have none.
+//
+// Parameters:
+//   - p: Byte slice containing the string
+//   - s: The letter mapping scheme
+//
+// Returns:
+//   - int64: The number of decodings
+func enumerateDecodings(p []byte, s *decodeways.Scheme) int64 {
+	digit := func(b byte) bool { return b >= 0x30 && b <= 0x39 }
+	var n int64
+	var walk func(i int)
+	walk = func(i int) {
+		if i == len(p) {
+			n++ // One complete decoding
+			return
+		}
+		if !digit(p[i]) {
+			return
+		}
+		if s.Single.Has(p[i] - 0x30) {
+			walk(i + 1)
+		}
+		if i+1 < len(p) && digit(p[i+1]) && s.Pair[p[i]-0x30].Has(p[i+1]-0x30) {
+			walk(i + 2)
+		}
+	}
+	if len(p) > 0 {
+		walk(0)
+	}
+	return n
+}
+
+// crossCheck compares the result of a count with an exhaustive enumeration
+// of the decodings (--cross-check), reporting both.
+//
+// Parameters:
+//   - c: The counter of the result
+//   - p: The input, of at most crossCheckMax characters
+//   - r: The result of c.countInput(p)
+//
+// Returns:
+//   - string: The report, e.g. "cross-check: count 10, enumeration 10, OK"
+//   - error: An error if the two disagree
+func (c *counter) crossCheck(p []byte, r string) (string, error) {
+	n := enumerateDecodings(p, c.s)
+	want := big.NewInt(n)
+	if c.mod != 0 {
+		want.Mod(want, new(big.Int).SetUint64(c.mod))
+	}
+	expected := want.String()
+	if c.atLeast != nil && want.Cmp(c.atLeast) >= 0 {
+		expected = "≥ " + c.atLeast.String()
+	}
+	if r != expected {
+		return "", fmt.Errorf("cross-check failed: the count is %s, but enumerating gives %s (%d decodings)", r, expected, n)
+	}
+	return fmt.Sprintf("cross-check: count %s, enumeration %d decodings, OK", r, n), nil
+}
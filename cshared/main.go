@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build cgo
+
+/*
+Command cshared exports the decode-ways counter as a C library, so that C,
+Python, Rust and other programs can count without running a subprocess.
+
+Build:
+
+	go build -buildmode=c-shared -o libdecodeways.so ./cshared
+
+which also writes libdecodeways.h declaring
+
+	char *decode_ways_count(char *digits, char **err);
+	void decode_ways_free(char *s);
+
+(cgo can not declare digits const, but it is not modified). decode_ways_count
+returns the number of decodings of the NUL-terminated
+digit string in decimal. If the input is invalid it returns NULL and, if err
+is not NULL, sets *err to the error message. Both strings are owned by the
+caller, who frees them with decode_ways_free (or free). The functions may be
+called from several threads at once: the Fibonacci numbers the counts share
+(see decodeways.Fib) are synchronized.
+*/
+package main
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"task1/decodeways"
+)
+
+// decode_ways_count counts the decodings of a NUL-terminated digit string.
+//
+//export decode_ways_count
+func decode_ways_count(digits *C.char, err **C.char) *C.char {
+	if digits == nil {
+		if err != nil {
+			*err = C.CString("digits is NULL")
+		}
+		return nil
+	}
+	factors := decodeways.Factors{}
+	p := []byte(C.GoString(digits))
+	if e := decodeways.ScanClusters(p, 0, &decodeways.StandardScheme, factors); e != nil {
+		if err != nil {
+			*err = C.CString(e.Error())
+		}
+		return nil
+	}
+	return C.CString(factors.Product().String())
+}
+
+// decode_ways_free frees a string returned by decode_ways_count.
+//
+//export decode_ways_free
+func decode_ways_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+func main() {} // Required by -buildmode=c-shared
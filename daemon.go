@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"task1/decodeways"
+)
+
+// handleDaemonConn answers the requests of one daemon client.
+//
+// The protocol is line-based. Every request is one line:
+//
+//	COUNT <file>       count the decodings of a file
+//	DIGITS <string>    count the decodings of the digit string itself
+//
+// and is answered by one line, "OK <count>" or "ERR <message>". A client may
+// send any number of requests over the same connection.
+//
+// Parameters:
+//   - c: The counter
+//   - conn: The client connection
+func handleDaemonConn(c *counter, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return // Closed by the client
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		var x string
+		cmd, arg, _ := bytes.Cut(line, []byte{' '})
+		switch string(cmd) {
+		case "COUNT":
+			x, err = countFile(c, string(arg), nil)
+		case "DIGITS":
+			x, err = c.countInput(arg)
+		default:
+			err = fmt.Errorf("unknown request %q", cmd)
+		}
+		if err != nil {
+			msg, _, _ := strings.Cut(err.Error(), "\n") // One line, without the snippet
+			fmt.Fprintf(w, "ERR %s\n", msg)
+		} else {
+			fmt.Fprintf(w, "OK %s\n", x)
+		}
+		if w.Flush() != nil {
+			return
+		}
+	}
+}
+
+// runDaemon implements the "daemon" subcommand: a long-running process
+// serving counts over a Unix domain socket.
+//
+// Short-lived local callers get the Fibonacci and result caches of a warm
+// process, both in memory and on disk, and pay neither process start-up nor
+// a TCP round trip. The socket is removed when the daemon is interrupted.
+//
+// Usage: decode-ways daemon --socket /tmp/decode-ways.sock
+//
+// Returns:
+//   - int: The process exit code
+func runDaemon(args []string) int {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	socket := fs.String("socket", "", "`path` of the Unix domain socket to listen on")
+	fibCache := fs.String("fib-cache", defaultCacheDir("fib"), "`dir` caching huge Fibonacci numbers across runs (empty to disable)")
+	fibCacheSize := fs.String("fib-cache-size", "1G", "evict the least recently used numbers when --fib-cache exceeds `size` (0 for no limit)")
+	resultCacheDir := fs.String("result-cache", defaultCacheDir("results"), "`dir` caching counts by input hash (empty to disable)")
+	resultCacheSize := fs.String("result-cache-size", "256M", "evict the least recently used counts when --result-cache exceeds `size` (0 for no limit)")
+	warmup := fs.Uint64("warmup", 0, "precompute the Fibonacci numbers up to index `n` before serving")
+	metricsAddr := fs.String("metrics-addr", "", "serve the expvar metrics at /debug/vars on `address` (e.g. localhost:6060)")
+	countOpts := addCountFlags(fs)
+	logOpts := addLogFlags(fs, "info")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways daemon --socket <path>")
+		fmt.Fprintln(os.Stderr, "Example: printf 'COUNT test.txt\\n' | nc -U /tmp/decode-ways.sock")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if *socket == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+	if err := setupLogging(*logOpts); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+
+	if dir, err := openCacheDir(*fibCache, *fibCacheSize); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --fib-cache-size: %v\n", trError(err))))
+		return 1
+	} else if dir != nil {
+		decodeways.SetFibCache(diskFibCache{dir})
+	}
+	if dir, err := openCacheDir(*resultCacheDir, *resultCacheSize); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --result-cache-size: %v\n", trError(err))))
+		return 1
+	} else {
+		resultCache = dir
+	}
+	showProgress = false
+	c, err := newCounter(*countOpts)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	if *warmup > 0 {
+		decodeways.Precompute(*warmup)
+	}
+	if *metricsAddr != "" {
+		serveMetrics(*metricsAddr)
+	}
+
+	// A socket left behind by a daemon that was killed would make Listen fail
+	if fi, err := os.Lstat(*socket); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(*socket)
+	}
+	l, err := net.Listen("unix", *socket)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		l.Close() // Also removes the socket file
+	}()
+
+	slog.Info("listening", "socket", *socket)
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return 0
+		}
+		if err != nil {
+			slog.Error("accept failed", "err", err)
+			return 1
+		}
+		go handleDaemonConn(c, conn)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// dagEdges calls fn for every valid decoding step of a digit string.
+//
+// The decoding DAG has a node for every position 0..n and an edge i -> i+1
+// for every valid one-digit code starting at i, and an edge i -> i+2 for every
+// valid two-digit code. Every path from 0 to n is a decoding.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - fn: Callback receiving the edge endpoints and the decoded letter
+//
+// Returns:
+//   - error: An error if p contains a non-digit character, or the first error returned by fn
+func dagEdges(p []byte, fn func(from, to int, letter byte) error) error {
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return fmt.Errorf("encountered non-digit character at pos. %d", i)
+		}
+		if b != 0x30 {
+			if err := fn(i, i+1, 'A'+b-0x31); err != nil {
+				return err
+			}
+		}
+		if i+1 < len(p) && p[i+1] >= 0x30 && p[i+1] <= 0x39 {
+			if v := (b-0x30)*10 + p[i+1] - 0x30; v >= 10 && v <= 26 {
+				if err := fn(i, i+2, 'A'+v-1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeDAG writes the decoding DAG of a digit string.
+//
+// Supported formats are "dot" (Graphviz) and "json", the latter being an
+// object with the node count and an adjacency list indexed by position.
+//
+// Parameters:
+//   - w: Destination writer
+//   - p: Byte slice containing the digit string
+//   - format: Output format, "dot" or "json"
+//
+// Returns:
+//   - error: An error if the format is unknown, the input is invalid or writing fails
+func writeDAG(w io.Writer, p []byte, format string) error {
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case "dot":
+		fmt.Fprintln(bw, "digraph decodings {")
+		fmt.Fprintln(bw, "  rankdir=LR;")
+		err = dagEdges(p, func(from, to int, letter byte) error {
+			_, err := fmt.Fprintf(bw, "  %d -> %d [label=\"%c\"];\n", from, to, letter)
+			return err
+		})
+		fmt.Fprintln(bw, "}")
+	case "json":
+		fmt.Fprintf(bw, "{\"nodes\":%d,\"adjacency\":[[", len(p)+1)
+		node := 0
+		first := true
+		err = dagEdges(p, func(from, to int, letter byte) error {
+			// Close the adjacency lists of the nodes before "from"
+			for ; node < from; node++ {
+				bw.WriteString("],[")
+				first = true
+			}
+			if !first {
+				bw.WriteByte(',')
+			}
+			first = false
+			_, err := fmt.Fprintf(bw, "{\"to\":%d,\"letter\":\"%c\"}", to, letter)
+			return err
+		})
+		for ; node < len(p); node++ {
+			bw.WriteString("],[")
+		}
+		bw.WriteString("]]}\n")
+	default:
+		return fmt.Errorf("unknown DAG format %q (expected dot or json)", format)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Builder counts the decodings of a digit string that grows over time.
+//
+// Unlike a Scanner, which gives a single count at the end, a Builder tells
+// the count of the input so far after every Append. The clusters closed by
+// the fragments appended so far are multiplied into a running product, so a
+// count costs one multiplication by the factor of the cluster still open,
+// however long the input is.
+//
+// Usage:
+//
+//	b := decodeways.NewBuilder(nil)
+//	b.Append([]byte("12"))
+//	n, err := b.Count() // 2
+//	b.Append([]byte("6"))
+//	n, err = b.Count() // 3
+type Builder struct {
+	st     *scanState
+	closed *big.Int // Product of the factors of the closed clusters
+}
+
+// NewBuilder returns a Builder of an empty input.
+//
+// Parameters:
+//   - s: The letter mapping scheme, nil for StandardScheme
+//
+// Returns:
+//   - *Builder: The new builder
+func NewBuilder(s *Scheme) *Builder {
+	if s == nil {
+		s = &StandardScheme
+	}
+	return &Builder{st: newScanState(s, 0), closed: big.NewInt(1)}
+}
+
+// Append adds a fragment to the end of the input. An invalid fragment is
+// rejected as a whole and leaves the builder unchanged, so the caller can go
+// on with a corrected one.
+//
+// Parameters:
+//   - p: The fragment of the digit string
+//
+// Returns:
+//   - error: An error if the fragment is invalid where it is appended
+func (b *Builder) Append(p []byte) error {
+	saved := *b.st
+	factors := Factors{}
+	if err := b.st.feed(p, factors); err != nil {
+		*b.st = saved
+		return err
+	}
+	if len(factors) > 0 {
+		b.closed.Mul(b.closed, factors.Product())
+	}
+	return nil
+}
+
+// Len returns the number of digits appended so far.
+func (b *Builder) Len() int64 {
+	return int64(b.st.n)
+}
+
+// Count returns the number of decodings of the input appended so far.
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if nothing was appended yet
+func (b *Builder) Count() (*big.Int, error) {
+	if b.st.n == 0 {
+		return big.NewInt(0), errors.New("empty string")
+	}
+	return new(big.Int).Mul(b.closed, Fib(b.st.clusterSize+2)), nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+// Cluster is a cluster of a digit string, as found by the cluster scan.
+type Cluster struct {
+	Start, End int    // Offsets of the digits of the cluster, p[Start:End]
+	Size       uint64 // Number of valid pairs, End-Start-1
+	Index      uint64 // Fibonacci index of the factor F(Size+2) the cluster contributes
+}
+
+// EachCluster validates a digit string and calls fn for each of its clusters,
+// in the order of the input: the same clusters whose factors ScanClusters
+// collects, but with their positions.
+//
+// A cluster of n pairs spans n+1 digits and contributes F(n+2). If a digit
+// that is not a code on its own (the '0' of 10 and 20) takes its last digit,
+// the cluster is reported without it, one pair shorter; a cluster cut down to
+// a single digit contributes F(2) = 1 and is not reported.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to scan
+//   - s: The letter mapping scheme
+//   - fn: The function called with every cluster
+//
+// Returns:
+//   - error: An error if the input is invalid, before fn is called
+func EachCluster(p []byte, s *Scheme, fn func(Cluster)) error {
+	if err := ScanClusters(p, 0, s, Factors{}); err != nil {
+		return err
+	}
+
+	var size uint64 // Size of the cluster open at i
+	start := 0      // Start of the open cluster
+	for i := 1; i < len(p); i++ {
+		a, b := p[i-1]-0x30, p[i]-0x30
+		pair := s.Pair[a].Has(b)
+		switch {
+		case !s.Single.Has(b):
+			// The pair takes the previous digit out of the cluster it ends
+			if size > 1 {
+				fn(Cluster{Start: start, End: i - 1, Size: size - 1, Index: size + 1})
+			}
+			size = 0
+		case pair:
+			if size == 0 {
+				start = i - 1
+			}
+			size++
+		case size > 0:
+			fn(Cluster{Start: start, End: i, Size: size, Index: size + 2})
+			size = 0
+		}
+	}
+	if size > 0 {
+		fn(Cluster{Start: start, End: len(p), Size: size, Index: size + 2})
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+/*
+Package decodeways counts the ways a string of digits can be decoded into
+letters, where 'A' -> 1, 'B' -> 2, ..., 'Z' -> 26.
+
+Consecutive digits that pair into valid two-digit codes form "clusters"; a
+cluster of size n (n valid pairs) has F(n+2) decodings, F being the Fibonacci
+sequence, and the total is the product over all clusters. The scan is a
+single pass in constant memory; the Fibonacci factors are multiplied at the
+end with a product tree.
+
+Whole inputs in memory are scanned with ScanClusters; data arriving over time,
+e.g. from a socket, is counted with a Scanner, which reads it through a small
+reusable buffer, or written to a Writer, which takes it like a hash.Hash.
+*/
+package decodeways
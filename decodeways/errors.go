@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import "fmt"
+
+// SyntaxError reports a character of a digit string that can not be decoded.
+//
+// Pos is the absolute byte offset of the character in the whole input, also
+// when the input is scanned in pieces (see ScanClusters and Scanner), so
+// callers can locate it, e.g. by line and column.
+type SyntaxError struct {
+	Msg string // Description, e.g. "encountered non-digit character"
+	Pos int    // Byte offset of the character, counted from 0
+}
+
+// Error implements error, e.g. "encountered non-digit character at pos. 12".
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at pos. %d", e.Msg, e.Pos)
+}
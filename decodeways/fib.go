@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// fibTableSize bounds the Fibonacci cache table. Indexes below it are
+// memoized; larger ones are computed by fast doubling, so a single huge
+// cluster costs O(log n) multiplications instead of n cached big.Ints.
+const fibTableSize = 1024
+
+// fibTable is the Fibonacci cache table storing precomputed Fibonacci
+// numbers, starting with F(0) = 0 and F(1) = 1 as base cases. It is extended
+// on demand by Fib and only ever grows: a longer table is published as a
+// whole, so readers need no lock, and the elements they can see are never
+// written again.
+var fibTable atomic.Pointer[[]*big.Int]
+
+// fibMu serializes the extensions of fibTable.
+var fibMu sync.Mutex
+
+func init() {
+	t := []*big.Int{big.NewInt(0), big.NewInt(1)}
+	fibTable.Store(&t)
+}
+
+// fibEntry is a Fibonacci number with its index.
+type fibEntry struct {
+	n uint64
+	x *big.Int
+}
+
+// lastFib holds the most recent Fibonacci number computed by fast doubling.
+// Huge clusters tend to repeat, e.g. in generated inputs.
+var lastFib atomic.Pointer[fibEntry]
+
+// Fib calculates and returns the nth Fibonacci number. It is safe for
+// concurrent use.
+//
+// The function uses big.Int to handle arbitrarily large Fibonacci numbers.
+// For reference, F(93) = 12,200,160,415,121,876,738 is the largest Fibonacci
+// number that fits in int64. The returned value is shared and must not be
+// modified.
+//
+// Parameters:
+//   - n: The index of the Fibonacci number to calculate (0-indexed)
+//
+// Returns:
+//   - *big.Int: The nth Fibonacci number
+//
+// Time Complexity: O(1) if cached, O(n - len(table)) additions below
+// fibTableSize, O(log n) multiplications above it unless found in the
+// FibCache
+func Fib(n uint64) *big.Int {
+	if n >= fibTableSize {
+		if e := lastFib.Load(); e != nil && e.n == n {
+			return e.x
+		}
+		var x *big.Int
+		if fibCache != nil {
+			x = fibCache.Load(n)
+		}
+		if x == nil {
+			x = fibDoubling(n)
+			if fibCache != nil {
+				fibCache.Store(n, x)
+			}
+		}
+		lastFib.Store(&fibEntry{n, x})
+		return x
+	}
+
+	if t := *fibTable.Load(); n < uint64(len(t)) {
+		return t[n]
+	}
+	// Extend the Fibonacci cache up to index n
+	fibMu.Lock()
+	defer fibMu.Unlock()
+	t := *fibTable.Load()
+	for uint64(len(t)) <= n {
+		// F(n) = F(n-1) + F(n-2)
+		t = append(t, new(big.Int).Add(t[len(t)-1], t[len(t)-2]))
+	}
+	fibTable.Store(&t)
+	return t[n]
+}
+
+// Precompute fills the Fibonacci cache up to index n, moving the cost out of
+// a later measured or latency-sensitive path (the first request of a server,
+// a benchmark).
+//
+// The table is filled up to min(n, fibTableSize-1); a larger n is computed by
+// fast doubling and kept as the most recent large value, and stored in the
+// FibCache if one is set. Like Fib, it is safe for concurrent use.
+//
+// Parameters:
+//   - n: The largest index to precompute
+func Precompute(n uint64) {
+	Fib(min(n, fibTableSize-1))
+	if n >= fibTableSize {
+		Fib(n)
+	}
+}
+
+// FibCache persists Fibonacci numbers too large for the in-memory table, e.g.
+// on disk, so that repeated runs do not recompute them. Fib calls it from any
+// goroutine, so it must be safe for concurrent use.
+type FibCache interface {
+	// Load returns F(n), or nil if it is not cached.
+	Load(n uint64) *big.Int
+	// Store saves F(n); it is best effort and may discard the value.
+	Store(n uint64, x *big.Int)
+}
+
+// fibCache is the FibCache in use, nil if none.
+var fibCache FibCache
+
+// SetFibCache sets the cache consulted for Fibonacci numbers of index
+// fibTableSize and above (nil to disable). It is not safe to call it
+// concurrently with Fib.
+func SetFibCache(c FibCache) {
+	fibCache = c
+}
+
+// fibDoubling calculates the nth Fibonacci number by fast doubling.
+//
+// Walking the bits of n from the top, the pair (F(k), F(k+1)) is doubled with
+//
+//	F(2k)   = F(k) * (2F(k+1) - F(k))
+//	F(2k+1) = F(k)^2 + F(k+1)^2
+//
+// and advanced by one whenever the bit is set. The values rotate through four
+// big.Ints taken from intPool, so no multiplication aliases its result with
+// an operand (which would make big.Int allocate a new backing array).
+//
+// Time Complexity: O(log n) big.Int multiplications
+func fibDoubling(n uint64) *big.Int {
+	a := intPool.Get().(*big.Int).SetInt64(0) // F(k)
+	b := intPool.Get().(*big.Int).SetInt64(1) // F(k+1)
+	t, u := intPool.Get().(*big.Int), intPool.Get().(*big.Int)
+	for i := bits.Len64(n) - 1; i >= 0; i-- {
+		t.Lsh(b, 1).Sub(t, a)
+		u.Mul(t, a) // F(2k)
+		t.Mul(a, a)
+		a.Mul(b, b)
+		b.Add(a, t) // F(2k+1)
+		a, u = u, a
+		if n>>uint(i)&1 == 1 {
+			t.Add(a, b)
+			a, b, t = b, t, a
+		}
+	}
+	intPool.Put(b)
+	intPool.Put(t)
+	intPool.Put(u)
+	return a
+}
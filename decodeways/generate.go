@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// Generator generates random digit strings for property tests, valid ones
+// with a tunable share of zeros and length of clusters, and invalid ones that
+// differ from a valid string in a single position.
+//
+// Usage:
+//
+//	g := decodeways.Generator{ZeroDensity: 0.1, MeanCluster: 4}
+//	r := rand.New(rand.NewSource(1))
+//	p := g.Valid(r, 1000)   // ScanClusters accepts p
+//	q := g.Invalid(r, 1000) // ScanClusters rejects q
+type Generator struct {
+	Scheme      *Scheme // The letter mapping scheme, nil for StandardScheme
+	ZeroDensity float64 // Probability of a digit that must be attached, like '0', wherever one fits
+	MeanCluster float64 // Mean number of pairs of a cluster, once started
+}
+
+// scheme returns the scheme of the generator.
+func (g *Generator) scheme() *Scheme {
+	if g.Scheme == nil {
+		return &StandardScheme
+	}
+	return g.Scheme
+}
+
+// pick returns a random digit character of a non-empty set.
+func pick(r *rand.Rand, set DigitSet) byte {
+	k := r.Intn(bits.OnesCount16(uint16(set)))
+	for d := byte(0); ; d++ {
+		if set.Has(d) {
+			if k == 0 {
+				return 0x30 + d
+			}
+			k--
+		}
+	}
+}
+
+// Valid generates a string of n digits that has at least one decoding
+// (n > 0). The scheme must have a one-digit code.
+//
+// Every digit is chosen so that the string so far stays decodable: a digit
+// that is not a code on its own is only placed after a digit it pairs with,
+// with probability ZeroDensity where possible. After a digit that starts a
+// pair, the cluster goes on with probability MeanCluster/(MeanCluster+1), so
+// the numbers of pairs of the clusters are geometric with mean MeanCluster.
+//
+// Parameters:
+//   - r: The source of randomness
+//   - n: Length of the string
+//
+// Returns:
+//   - []byte: The generated digit string
+func (g *Generator) Valid(r *rand.Rand, n int) []byte {
+	s := g.scheme()
+	var starters DigitSet // Digits that start a two-digit code
+	for d := byte(0); d <= 9; d++ {
+		if s.Pair[d] != 0 {
+			starters |= 1 << d
+		}
+	}
+	extend := g.MeanCluster / (g.MeanCluster + 1)
+
+	p := make([]byte, 0, n)
+	for len(p) < n {
+		if len(p) == 0 {
+			p = append(p, pick(r, s.Single))
+			continue
+		}
+		a := p[len(p)-1] - 0x30
+		zeros := s.Pair[a] &^ s.Single
+		pairs := s.Pair[a] & s.Single
+		switch {
+		case zeros != 0 && r.Float64() < g.ZeroDensity:
+			p = append(p, pick(r, zeros))
+		case pairs != 0 && r.Float64() < extend:
+			// Prefer digits that start the next pair, so the cluster goes on
+			if pairs&starters != 0 {
+				pairs &= starters
+			}
+			p = append(p, pick(r, pairs))
+		case s.Single&^s.Pair[a] != 0:
+			p = append(p, pick(r, s.Single&^s.Pair[a]))
+		default:
+			p = append(p, pick(r, s.Single))
+		}
+	}
+	return p
+}
+
+// Invalid generates a string of n characters that has no decoding: a valid
+// string (see Valid) with one character replaced by a digit that can not be
+// decoded there or, failing that, by a non-digit. For n = 0 it is the empty
+// string.
+//
+// Parameters:
+//   - r: The source of randomness
+//   - n: Length of the string
+//
+// Returns:
+//   - []byte: The generated string
+func (g *Generator) Invalid(r *rand.Rand, n int) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+	s := g.scheme()
+	p := g.Valid(r, n)
+	i := r.Intn(n)
+	// A digit that is no code on its own and pairs with neither neighbour
+	// leaves the prefix up to it, and thus the whole string, undecodable
+	bad := ^s.Single & 0x3FF
+	if i > 0 {
+		bad &^= s.Pair[p[i-1]-0x30]
+	}
+	for d := byte(0); d <= 9 && i+1 < n; d++ {
+		if s.Pair[d].Has(p[i+1] - 0x30) {
+			bad &^= 1 << d
+		}
+	}
+	if bad != 0 && r.Intn(4) != 0 {
+		p[i] = pick(r, bad)
+	} else {
+		p[i] = " \nx-+"[r.Intn(5)]
+	}
+	return p
+}
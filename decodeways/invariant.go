@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// bruteForceMax is the longest input CheckInvariant enumerates decoding by
+// decoding; a string of n digits has at most F(n+1) decodings.
+const bruteForceMax = 24
+
+// CheckInvariant checks the cluster count of a digit string against an
+// independent oracle: for strings of up to 24 digits, an enumeration of all
+// decodings one by one, and for longer ones the classic two-variable DP over
+// prefixes. The cluster scan must reject exactly the strings that have no
+// decoding (including the empty string) and count the others exactly.
+//
+// It is meant for fuzzing and property tests of the library and of custom
+// schemes: the DP costs O(n^2) for n digits, as its values grow with the
+// input, so the inputs should be short.
+//
+// Parameters:
+//   - p: Byte slice containing the string, not necessarily valid
+//   - s: The letter mapping scheme, nil for StandardScheme
+//
+// Returns:
+//   - error: An error describing the disagreement, nil if the counts agree
+func CheckInvariant(p []byte, s *Scheme) error {
+	if s == nil {
+		s = &StandardScheme
+	}
+	oracle, want := "DP", countDP(p, s)
+	if len(p) <= bruteForceMax {
+		oracle, want = "enumeration", countBruteForce(p, s)
+	}
+
+	factors := Factors{}
+	if err := ScanClusters(p, 0, s, factors); err != nil {
+		if want.Sign() != 0 {
+			return fmt.Errorf("the cluster scan rejects %q (%v), but the %s finds %v decodings", p, err, oracle, want)
+		}
+		return nil
+	}
+	if got := factors.Product(); got.Cmp(want) != 0 {
+		return fmt.Errorf("the cluster scan counts %v decodings of %q, but the %s finds %v", got, p, oracle, want)
+	}
+	return nil
+}
+
+// single reports whether p[i] is a one-digit code.
+func (s *Scheme) single(p []byte, i int) bool {
+	return p[i] >= 0x30 && p[i] <= 0x39 && s.Single.Has(p[i]-0x30)
+}
+
+// pair reports whether p[i-1] p[i] is a two-digit code.
+func (s *Scheme) pair(p []byte, i int) bool {
+	a, b := p[i-1], p[i]
+	return a >= 0x30 && a <= 0x39 && b >= 0x30 && b <= 0x39 && s.Pair[a-0x30].Has(b-0x30)
+}
+
+// countBruteForce counts the decodings of a string by enumerating them, one
+// leaf of the recursion per decoding. The empty string counts as having none,
+// as the cluster scan rejects it.
+func countBruteForce(p []byte, s *Scheme) *big.Int {
+	if len(p) == 0 {
+		return new(big.Int)
+	}
+	var walk func(i int) int64 // Decodings of p[i:]
+	walk = func(i int) int64 {
+		if i == len(p) {
+			return 1
+		}
+		var n int64
+		if s.single(p, i) {
+			n += walk(i + 1)
+		}
+		if i+1 < len(p) && s.pair(p, i+1) {
+			n += walk(i + 2)
+		}
+		return n
+	}
+	return big.NewInt(walk(0))
+}
+
+// countDP counts the decodings of a string with the classic DP: the count of
+// a prefix is that of the prefix one digit shorter, if its last digit is a
+// code, plus that of the prefix two digits shorter, if its last two digits
+// are. The empty string counts as having none, as the cluster scan rejects it.
+func countDP(p []byte, s *Scheme) *big.Int {
+	if len(p) == 0 {
+		return new(big.Int)
+	}
+	prev, cur := big.NewInt(1), new(big.Int) // Counts of p[:i-1] and p[:i]
+	if s.single(p, 0) {
+		cur.SetInt64(1)
+	}
+	for i := 1; i < len(p); i++ {
+		next := new(big.Int)
+		if s.single(p, i) {
+			next.Set(cur)
+		}
+		if s.pair(p, i) {
+			next.Add(next, prev)
+		}
+		prev, cur = cur, next
+	}
+	return cur
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"bytes"
+	"testing"
+)
+
+// invariantSeeds are inputs around the edge cases of the cluster scan:
+// zeros that attach or not, codes at the edge of the pairs, and non-digits.
+var invariantSeeds = []string{
+	"", "0", "10", "20", "30", "06", "100", "2101", "226", "27", "1111111111",
+	"110110", "2626262626", "1a2", "12 34", "9999",
+}
+
+// FuzzCheckInvariant checks the cluster scan against the oracle of
+// CheckInvariant: the enumeration of every decoding for short inputs, the DP
+// for longer ones.
+//
+//	go test ./decodeways -fuzz FuzzCheckInvariant
+func FuzzCheckInvariant(f *testing.F) {
+	for _, s := range invariantSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, p []byte) {
+		if err := CheckInvariant(p, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// FuzzCountDP checks the cluster scan and the streaming Scanner against the
+// DP on inputs too long to enumerate: the fuzzed bytes are repeated into an
+// input of more than bruteForceMax digits, so that clusters grow past the
+// sizes of the short inputs.
+//
+//	go test ./decodeways -fuzz FuzzCountDP
+func FuzzCountDP(f *testing.F) {
+	for i, s := range invariantSeeds {
+		f.Add([]byte(s), uint8(i*17))
+	}
+	f.Fuzz(func(t *testing.T, p []byte, reps uint8) {
+		if len(p) > 256 {
+			p = p[:256] // The DP costs O(n^2): keep inputs short
+		}
+		q := bytes.Repeat(p, int(reps)%32+1)
+		if len(q) <= bruteForceMax {
+			q = append(bytes.Repeat([]byte{0x31}, bruteForceMax+1-len(q)), q...)
+		}
+		if err := CheckInvariant(q, nil); err != nil {
+			t.Fatal(err)
+		}
+		want := countDP(q, &StandardScheme)
+		got, err := NewScanner(bytes.NewReader(q), &StandardScheme).Count()
+		switch {
+		case err != nil && want.Sign() != 0:
+			t.Fatalf("the Scanner rejects %q (%v), but the DP finds %v decodings", q, err, want)
+		case err == nil && got.Cmp(want) != 0:
+			t.Fatalf("the Scanner counts %v decodings of %q, but the DP finds %v", got, q, want)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// intPool recycles big.Int temporaries. Their backing arrays are the large
+// allocations in the multiply phase, and multiplying into a fresh value
+// (big.Int can not multiply in place) would otherwise allocate on every step.
+var intPool = sync.Pool{New: func() any { return new(big.Int) }}
+
+// Factors maps a Fibonacci index k to the number of clusters that
+// contribute a factor F(k) to the number of decodings.
+//
+// Real inputs consist of a huge number of small clusters, so the counts stay
+// small (one entry per distinct cluster size) however long the input is.
+type Factors map[uint64]uint64
+
+// Product calculates the product of F(k)^c over all entries.
+//
+// Multiplying factors one by one into a running product costs O(r^2) for a
+// result of r words, as every step multiplies the whole product so far.
+// Instead, each distinct factor is raised to its multiplicity by repeated
+// squaring, and the powers are combined with a balanced product tree, so
+// the multiplication works on operands of similar size where its
+// subquadratic algorithms pay off. The arithmetic itself is done by
+// powerProduct, math/big by default or GMP when built with the gmp tag.
+//
+// Returns:
+//   - *big.Int: The product (1 if there are no factors)
+func (fc Factors) Product() *big.Int {
+	bases := make([]*big.Int, 0, len(fc))
+	exps := make([]uint64, 0, len(fc))
+	for k, c := range fc {
+		bases = append(bases, Fib(k))
+		exps = append(exps, c)
+	}
+	return powerProduct(bases, exps)
+}
+
+// AtLeast decides whether the product is at least k, stopping as soon as it
+// provably is.
+//
+// Only the bit lengths of the factors are needed to tell that a product
+// exceeds k: a factor of b bits is at least 2^(b-1). The factors are
+// multiplied into a running product, largest first, and of every power only
+// as much is computed as that bound requires to pass k. The running product
+// thus never grows much beyond k, however large the full product would be.
+//
+// Parameters:
+//   - k: The threshold, not negative
+//
+// Returns:
+//   - *big.Int: The product if it is below k, otherwise nil
+//   - bool: Whether the product is at least k
+func (fc Factors) AtLeast(k *big.Int) (*big.Int, bool) {
+	keys := make([]uint64, 0, len(fc))
+	for f := range fc {
+		if f > 2 { // F(1) = F(2) = 1
+			keys = append(keys, f)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] > keys[j] })
+
+	x := big.NewInt(1)
+	t, e := new(big.Int), new(big.Int)
+	for _, f := range keys {
+		if x.Cmp(k) >= 0 {
+			return nil, true
+		}
+		b := Fib(f)
+		// x * b^n >= 2^(x.BitLen()-1 + n*(b.BitLen()-1)), which passes k
+		// once the exponent of 2 reaches k.BitLen()
+		bits := uint64(b.BitLen() - 1)
+		need := (uint64(k.BitLen()-x.BitLen()+1) + bits - 1) / bits
+		n := min(fc[f], need)
+		x.Mul(x, t.Exp(b, e.SetUint64(n), nil))
+	}
+	if x.Cmp(k) >= 0 {
+		return nil, true
+	}
+	return x, false
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build !gmp
+
+package decodeways
+
+import (
+	"math/big"
+	"sort"
+)
+
+// powerProduct calculates the product of bases[i]^exps[i] with math/big.
+//
+// Parameters:
+//   - bases: The bases, shared values that are not modified
+//   - exps: The exponents, positive
+//
+// Returns:
+//   - *big.Int: The product (1 if there are no bases)
+func powerProduct(bases []*big.Int, exps []uint64) *big.Int {
+	terms := make([]*big.Int, len(bases))
+	e := new(big.Int)
+	for i, b := range bases {
+		t := intPool.Get().(*big.Int)
+		if exps[i] == 1 {
+			t.Set(b)
+		} else {
+			t.Exp(b, e.SetUint64(exps[i]), nil)
+		}
+		terms[i] = t
+	}
+	return productTree(terms)
+}
+
+// productTree multiplies the numbers pairwise, level by level, until one is
+// left. The numbers are sorted by size first so that the pairs are balanced.
+// The slice is overwritten and the numbers are recycled through intPool, so
+// the caller must own them.
+func productTree(t []*big.Int) *big.Int {
+	if len(t) == 0 {
+		return big.NewInt(1)
+	}
+	sort.Slice(t, func(i, j int) bool { return t[i].BitLen() < t[j].BitLen() })
+	for len(t) > 1 {
+		n := len(t) / 2
+		for i := 0; i < n; i++ {
+			z := intPool.Get().(*big.Int)
+			z.Mul(t[2*i], t[2*i+1])
+			intPool.Put(t[2*i])
+			intPool.Put(t[2*i+1])
+			t[i] = z
+		}
+		if len(t)%2 == 1 {
+			t[n] = t[len(t)-1]
+			n++
+		}
+		t = t[:n]
+	}
+	return t[0]
+}
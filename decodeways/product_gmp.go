@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build gmp
+
+package decodeways
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ncw/gmp"
+)
+
+// powerProduct calculates the product of bases[i]^exps[i] with GMP.
+//
+// For answers with millions of digits GMP multiplies several times faster
+// than math/big (FFT multiplication and hand-tuned assembly), so the whole
+// multiply phase, powers included, is done in GMP and only the result is
+// converted back. Requires cgo and libgmp; build with "go build -tags gmp".
+//
+// Parameters:
+//   - bases: The bases, shared values that are not modified
+//   - exps: The exponents, positive
+//
+// Returns:
+//   - *big.Int: The product (1 if there are no bases)
+func powerProduct(bases []*big.Int, exps []uint64) *big.Int {
+	if len(bases) == 0 {
+		return big.NewInt(1)
+	}
+	t := make([]*gmp.Int, len(bases))
+	e := new(gmp.Int)
+	for i, b := range bases {
+		t[i] = new(gmp.Int).SetBytes(b.Bytes())
+		if exps[i] > 1 {
+			e.SetUint64(exps[i])
+			t[i].Exp(t[i], e, nil)
+		}
+	}
+
+	// Balanced product tree, as in the math/big backend
+	sort.Slice(t, func(i, j int) bool { return t[i].BitLen() < t[j].BitLen() })
+	for len(t) > 1 {
+		n := len(t) / 2
+		for i := 0; i < n; i++ {
+			t[i] = new(gmp.Int).Mul(t[2*i], t[2*i+1])
+		}
+		if len(t)%2 == 1 {
+			t[n] = t[len(t)-1]
+			n++
+		}
+		t = t[:n]
+	}
+	return new(big.Int).SetBytes(t[0].Bytes())
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// resultVersion is the version of the binary form of a Result.
+const resultVersion = 1
+
+// Result is the number of decodings of an input with its metadata.
+//
+// Its binary form (MarshalBinary) holds the count as big-endian bytes, so
+// programs that consume huge counts are spared the conversion to decimal and
+// back, which is slow for counts of millions of digits. Its text form is the
+// count in decimal, and its JSON form an object with the count as a decimal
+// string, which JSON numbers can not hold exactly:
+//
+//	{"count":"10","length":14}
+type Result struct {
+	Count  *big.Int // The number of decodings
+	Length int64    // Length of the input in bytes
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a
+// version byte, the length of the input and the number of bytes of the count
+// as uvarints, then the count as big-endian bytes; it is self-delimiting, so
+// results can be written one after another.
+//
+// Returns:
+//   - []byte: The binary form
+//   - error: An error if the count is nil or negative
+func (r Result) MarshalBinary() ([]byte, error) {
+	if r.Count == nil || r.Count.Sign() < 0 {
+		return nil, errors.New("decodeways: Result without a count")
+	}
+	n := (r.Count.BitLen() + 7) / 8
+	b := make([]byte, 0, 1+2*binary.MaxVarintLen64+n)
+	b = append(b, resultVersion)
+	b = binary.AppendUvarint(b, uint64(r.Length))
+	b = binary.AppendUvarint(b, uint64(n))
+	b = append(b, make([]byte, n)...)
+	r.Count.FillBytes(b[len(b)-n:])
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// Parameters:
+//   - data: The binary form, see MarshalBinary
+//
+// Returns:
+//   - error: An error if data is not the binary form of a Result
+func (r *Result) UnmarshalBinary(data []byte) error {
+	rest, err := r.decode(data)
+	if err == nil && len(rest) > 0 {
+		err = errors.New("decodeways: data after the Result")
+	}
+	return err
+}
+
+// ReadBinary decodes the first of the Results of a sequence of binary forms.
+//
+// Parameters:
+//   - data: The binary forms, one after another
+//
+// Returns:
+//   - []byte: The rest of data, after the Result
+//   - error: An error if data does not start with the binary form of a Result
+func (r *Result) ReadBinary(data []byte) ([]byte, error) {
+	return r.decode(data)
+}
+
+// decode decodes the binary form at the start of data and returns the rest.
+func (r *Result) decode(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != resultVersion {
+		return nil, errors.New("decodeways: not a Result, or of an unknown version")
+	}
+	data = data[1:]
+	length, k := binary.Uvarint(data)
+	if k <= 0 {
+		return nil, errors.New("decodeways: truncated Result")
+	}
+	data = data[k:]
+	n, k := binary.Uvarint(data)
+	if k <= 0 || uint64(len(data)-k) < n {
+		return nil, errors.New("decodeways: truncated Result")
+	}
+	data = data[k:]
+	r.Count = new(big.Int).SetBytes(data[:n])
+	r.Length = int64(length)
+	return data[n:], nil
+}
+
+// resultJSON is the JSON form of a Result.
+type resultJSON struct {
+	Count  string `json:"count"`
+	Length int64  `json:"length"`
+}
+
+// String implements fmt.Stringer: the count in decimal, "<nil>" without a
+// count.
+func (r Result) String() string {
+	return r.Count.String()
+}
+
+// MarshalText implements encoding.TextMarshaler: the count in decimal.
+//
+// Returns:
+//   - []byte: The text form
+//   - error: An error if the count is nil
+func (r Result) MarshalText() ([]byte, error) {
+	if r.Count == nil {
+		return nil, errors.New("decodeways: Result without a count")
+	}
+	return r.Count.Append(nil, 10), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The length of the input
+// is not part of the text form and is set to 0.
+//
+// Parameters:
+//   - text: The count in decimal
+//
+// Returns:
+//   - error: An error if text is not a count in decimal
+func (r *Result) UnmarshalText(text []byte) error {
+	x, ok := new(big.Int).SetString(string(text), 10)
+	if !ok || x.Sign() < 0 {
+		return fmt.Errorf("decodeways: invalid count %q", text)
+	}
+	r.Count, r.Length = x, 0
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler: an object with the count as a
+// decimal string and the length of the input.
+//
+// Returns:
+//   - []byte: The JSON form
+//   - error: An error if the count is nil
+func (r Result) MarshalJSON() ([]byte, error) {
+	text, err := r.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resultJSON{Count: string(text), Length: r.Length})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// Parameters:
+//   - data: The JSON form, see MarshalJSON
+//
+// Returns:
+//   - error: An error if data is not the JSON form of a Result
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var v resultJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := r.UnmarshalText([]byte(v.Count)); err != nil {
+		return err
+	}
+	r.Length = v.Length
+	return nil
+}
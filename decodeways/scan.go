@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"errors"
+	"fmt"
+)
+
+// scanState is the state of a cluster scan between two pieces of the input.
+type scanState struct {
+	s           *Scheme
+	q           byte   // Smallest quiet digit, see quietFrom
+	a           byte   // Previous digit (for pair checking)
+	clusterSize uint64 // Current size of the cluster being processed
+	n           int    // Number of digits consumed so far
+	off         int    // Position of the input, used in error messages
+}
+
+// newScanState starts a scan with the given scheme.
+func newScanState(s *Scheme, off int) *scanState {
+	return &scanState{s: s, q: s.quietFrom(), off: off}
+}
+
+// feed scans the next piece of the input, adding the factors of the clusters
+// it closes.
+//
+// A cluster is a sequence of consecutive digits where each pair is in the
+// range 11-19 or 21-26. These are the only two-digit combinations that can
+// validly be decoded either as two separate letters or as one letter. A digit
+// that is not a code on its own (the '0' of 10 and 20) must be attached to the
+// previous digit, which is then taken out of the cluster it ends.
+//
+// Parameters:
+//   - p: The next piece of the digit string
+//   - factors: Factor counts the clusters are added to
+//
+// Returns:
+//   - error: An error if the input is invalid
+func (st *scanState) feed(p []byte, factors Factors) error {
+	if len(p) == 0 {
+		return nil
+	}
+	s := st.s
+
+	if st.n == 0 {
+		// Validate first character: must be a digit that is a code on its own
+		a := p[0]
+		if a < 0x30 || a > 0x39 { // Not '0'-'9'
+			return &SyntaxError{"string starts with non-digit character", st.off}
+		} else if !s.Single.Has(a - 0x30) {
+			return &SyntaxError{fmt.Sprintf("string starts with %c", a), st.off}
+		}
+		st.a, st.n = a, 1
+		p = p[1:]
+	}
+
+	// Process each subsequent digit
+	a, clusterSize, q := st.a, st.clusterSize, st.q
+	pos := st.off + st.n // Position of p[0] in error messages
+	for i := 0; i < len(p); i++ {
+		// Fast path: if the previous digit starts no pair, a word of 8 quiet
+		// digits (e.g. "34567893") closes any open cluster and decodes in
+		// exactly one way, so it is validated and skipped at once
+		if a >= q && i+8 <= len(p) && allQuiet(p[i:], q) {
+			if clusterSize > 0 {
+				factors[clusterSize+2]++
+				clusterSize = 0
+			}
+			a = p[i+7]
+			i += 7
+			continue
+		}
+
+		b := p[i]
+		// Validate that current character is a digit
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return &SyntaxError{"encountered non-digit character", pos + i}
+		}
+
+		pair := s.Pair[a-0x30].Has(b - 0x30)
+		switch {
+		case !s.Single.Has(b - 0x30):
+			// The digit can only be decoded together with the previous one
+			// (e.g. '0' can only appear after '1' or '2', forming 10 or 20)
+			if !pair {
+				return &SyntaxError{fmt.Sprintf("encountered %c which can not be attached to %c", b, a), pos + i}
+			}
+			// The previous digit is taken by the pair, so the cluster it
+			// ends loses its last digit: multiply result by F(clusterSize + 1)
+			if clusterSize > 0 {
+				factors[clusterSize+1]++
+				clusterSize = 0
+			}
+		case pair:
+			// We are inside a cluster: the pair can be decoded in 2 ways
+			clusterSize++
+		case clusterSize > 0:
+			// We've exited a cluster: multiply result by F(clusterSize + 2)
+			// The +2 offset is because a cluster of size 1 has F(3) = 2 ways
+			factors[clusterSize+2]++
+			clusterSize = 0 // Reset cluster size
+		}
+
+		a = b // Move to next digit
+	}
+
+	st.a, st.clusterSize = a, clusterSize
+	st.n += len(p)
+	return nil
+}
+
+// finish ends the scan, adding the factor of a cluster still open.
+//
+// Parameters:
+//   - factors: Factor counts the cluster is added to
+//
+// Returns:
+//   - error: An error if the input was empty
+func (st *scanState) finish(factors Factors) error {
+	if st.n == 0 {
+		return errors.New("empty string")
+	}
+
+	// Handle the case where the string ends inside a cluster
+	if st.clusterSize > 0 {
+		factors[st.clusterSize+2]++
+		st.clusterSize = 0
+	}
+	return nil
+}
+
+// ScanClusters validates a digit string and collects the Fibonacci factors of
+// its clusters: a cluster of n valid pairs adds F(n+2), or F(n+1) if its last
+// digit is taken by a following '0'. The number of decodings is the product
+// of the factors, see Factors.Product.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to scan
+//   - off: Position of p in the whole input, used in error messages
+//   - s: The letter mapping scheme
+//   - factors: Factor counts the clusters are added to
+//
+// Returns:
+//   - error: An error if the input is invalid
+//
+// Example:
+//   - "12" -> cluster "12" (size 1) -> F(3) = 2 ways
+//   - "226" -> cluster "226" (size 2, as 22 and 26 are valid pairs) -> F(4) = 3 ways
+//   - "110" -> the last '1' is taken by "10", leaving "1" (size 0) -> F(2) = 1 way
+func ScanClusters(p []byte, off int, s *Scheme, factors Factors) error {
+	st := newScanState(s, off)
+	if err := st.feed(p, factors); err != nil {
+		return err
+	}
+	return st.finish(factors)
+}
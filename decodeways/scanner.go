@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"io"
+	"math/big"
+)
+
+// scanBufSize is the size of the buffer a Scanner reads through.
+const scanBufSize = 64 << 10
+
+// Scanner counts the decodings of a digit string read from an io.Reader.
+//
+// The data is read through a small buffer that is reused for every read, and
+// the scan state (the previous digit and the open cluster) is carried from one
+// read to the next, so the input is never held in memory as a whole: data
+// arriving from a socket can be counted as it comes. Only the Fibonacci
+// factors are kept, one entry per distinct cluster size.
+//
+// Usage:
+//
+//	sc := decodeways.NewScanner(conn, nil)
+//	n, err := sc.Count()
+type Scanner struct {
+	r       io.Reader
+	buf     []byte // Read buffer, allocated by Count
+	st      *scanState
+	factors Factors
+}
+
+// NewScanner returns a Scanner reading from r.
+//
+// Parameters:
+//   - r: The reader supplying the digit string, nil if it is pushed with Feed
+//   - s: The letter mapping scheme, nil for StandardScheme
+//
+// Returns:
+//   - *Scanner: The new scanner
+func NewScanner(r io.Reader, s *Scheme) *Scanner {
+	if s == nil {
+		s = &StandardScheme
+	}
+	return &Scanner{
+		r:       r,
+		st:      newScanState(s, 0),
+		factors: Factors{},
+	}
+}
+
+// ScanState is a snapshot of a scan, enough to resume it later, e.g. after a
+// reboot: the position, the last digit and the cluster open there, and the
+// factors of the clusters closed so far.
+type ScanState struct {
+	Offset      int64   // Number of bytes consumed
+	Prev        byte    // Last digit consumed (meaningless if Offset is 0)
+	ClusterSize uint64  // Size of the cluster open at Offset
+	Factors     Factors // Factors of the clusters closed before Offset
+}
+
+// State returns a snapshot of the scan. The factors are copied, so the
+// snapshot stays valid while the scan goes on.
+func (sc *Scanner) State() ScanState {
+	f := make(Factors, len(sc.factors))
+	for k, c := range sc.factors {
+		f[k] = c
+	}
+	return ScanState{
+		Offset:      int64(sc.st.n),
+		Prev:        sc.st.a,
+		ClusterSize: sc.st.clusterSize,
+		Factors:     f,
+	}
+}
+
+// Resume continues a scan from a snapshot taken by State. The reader, if
+// any, must supply the input from st.Offset on.
+func (sc *Scanner) Resume(st ScanState) {
+	sc.st.n, sc.st.a, sc.st.clusterSize = int(st.Offset), st.Prev, st.ClusterSize
+	sc.factors = make(Factors, len(st.Factors))
+	for k, c := range st.Factors {
+		sc.factors[k] = c
+	}
+}
+
+// Feed scans the next piece of the input. It lets callers push data instead
+// of having the scanner read it, in which case the reader may be nil; the
+// result is then taken with Result.
+//
+// Parameters:
+//   - p: The next piece of the digit string
+//
+// Returns:
+//   - error: An error if the input is invalid
+func (sc *Scanner) Feed(p []byte) error {
+	return sc.st.feed(p, sc.factors)
+}
+
+// Result ends the scan and returns the number of decodings of all the input
+// fed or read so far.
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if the input was empty
+func (sc *Scanner) Result() (*big.Int, error) {
+	if err := sc.st.finish(sc.factors); err != nil {
+		return big.NewInt(0), err
+	}
+	return sc.factors.Product(), nil
+}
+
+// Count reads r until io.EOF and returns the number of decodings.
+//
+// Error positions are counted from the start of the stream. Errors of the
+// reader other than io.EOF are returned as they are.
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if reading fails or the input is invalid
+func (sc *Scanner) Count() (*big.Int, error) {
+	if sc.buf == nil {
+		sc.buf = make([]byte, scanBufSize)
+	}
+	for {
+		n, err := sc.r.Read(sc.buf)
+		if ferr := sc.Feed(sc.buf[:n]); ferr != nil {
+			return big.NewInt(0), ferr
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return big.NewInt(0), err
+		}
+	}
+	return sc.Result()
+}
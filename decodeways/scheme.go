@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+// DigitSet is a set of digits stored as a bitmask, bit d standing for digit d.
+type DigitSet uint16
+
+// Has reports whether digit d (0-9) is in the set.
+func (s DigitSet) Has(d byte) bool {
+	return s&(1<<d) != 0
+}
+
+// Scheme describes which one- and two-digit codes a letter mapping has.
+//
+// The cluster algorithm only needs to know, for every digit, whether it is a
+// code on its own, and for every pair of digits, whether it is a two-digit code.
+type Scheme struct {
+	Single DigitSet     // Digits that are valid one-digit codes
+	Pair   [10]DigitSet // Pair[a] holds the digits b such that "ab" is a valid code
+}
+
+// StandardScheme is the classic mapping 'A' -> 1, ..., 'Z' -> 26.
+var StandardScheme = Scheme{
+	Single: 0x3FE, // 1-9
+	Pair: [10]DigitSet{
+		1: 0x3FF, // 10-19
+		2: 0x07F, // 20-26
+	},
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import "encoding/binary"
+
+// Byte-wise constants for SWAR (SIMD within a register) tests on 8 bytes.
+const (
+	swarOnes = 0x0101010101010101 // 0x01 in every byte
+	swarHigh = 0x8080808080808080 // 0x80 in every byte
+)
+
+// quietFrom returns the smallest digit character q such that every digit
+// from q to '9' is a code on its own and starts no two-digit code.
+//
+// A run of such "quiet" digits has exactly one decoding and never touches a
+// cluster, so the scanner can skip it without looking at the digits one by
+// one. For the standard scheme it is '3'. If no digit is quiet, 0x3A is
+// returned and the fast path never applies.
+func (s *Scheme) quietFrom() byte {
+	q := byte(0x30)
+	for d := byte(0); d <= 9; d++ {
+		if !s.Single.Has(d) || s.Pair[d] != 0 {
+			q = 0x31 + d
+		}
+	}
+	return q
+}
+
+// allQuiet reports whether all 8 bytes of p[:8] lie in the range q..'9'.
+//
+// The bytes are tested in parallel as a single little-endian uint64:
+//   - no byte has its high bit set (it is ASCII)
+//   - x + (0x7F-0x39) has no high bit set in any byte, i.e. every byte <= '9'
+//   - (x | 0x80) - q keeps the high bit in every byte, i.e. every byte >= q
+//
+// None of the byte-wise additions or subtractions can carry or borrow into
+// the neighbouring byte given the first condition.
+//
+// Parameters:
+//   - p: Byte slice of at least 8 bytes
+//   - q: Lower bound of the range, as returned by quietFrom
+//
+// Returns:
+//   - bool: True if every byte is a digit not below q
+func allQuiet(p []byte, q byte) bool {
+	x := binary.LittleEndian.Uint64(p)
+	if x&swarHigh != 0 {
+		return false
+	}
+	if (x+(0x7F-0x39)*swarOnes)&swarHigh != 0 {
+		return false
+	}
+	return ((x|swarHigh)-uint64(q)*swarOnes)&swarHigh == swarHigh
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package decodeways
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Writer counts the decodings of the digit string written to it, in the
+// manner of hash.Hash: the input is written in chunks of any size, e.g. by
+// io.Copy or an io.MultiWriter next to a real hash, and Sum returns the count
+// of all of it so far without ending the input, so writing can go on.
+//
+// Usage:
+//
+//	w := decodeways.NewWriter(nil)
+//	if _, err := io.Copy(w, f); err != nil {
+//		return err
+//	}
+//	n, err := w.Sum()
+//
+// Unlike hash.Hash, an invalid input fails the Write that reaches it. The
+// error is sticky: every later Write and Sum returns it, until Reset.
+type Writer struct {
+	s       *Scheme
+	st      *scanState
+	factors Factors
+	err     error // The first error of a Write
+}
+
+// NewWriter returns a Writer of an empty input.
+//
+// Parameters:
+//   - s: The letter mapping scheme, nil for StandardScheme
+//
+// Returns:
+//   - *Writer: The new writer
+func NewWriter(s *Scheme) *Writer {
+	if s == nil {
+		s = &StandardScheme
+	}
+	return &Writer{s: s, st: newScanState(s, 0), factors: Factors{}}
+}
+
+// Write implements io.Writer: it scans the next chunk of the input.
+//
+// Parameters:
+//   - p: The next chunk of the digit string
+//
+// Returns:
+//   - int: len(p), or 0 on an error
+//   - error: An error if the input is invalid, with its absolute position
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.st.feed(p, w.factors); err != nil {
+		w.err = err
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sum returns the number of decodings of the input written so far. It does
+// not change the state of the Writer, so more input can be written after it.
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: The error of a Write, or an error if nothing was written yet
+func (w *Writer) Sum() (*big.Int, error) {
+	if w.err != nil {
+		return big.NewInt(0), w.err
+	}
+	if w.st.n == 0 {
+		return big.NewInt(0), errors.New("empty string")
+	}
+	x := w.factors.Product()
+	if w.st.clusterSize > 0 {
+		x.Mul(x, Fib(w.st.clusterSize+2)) // The cluster still open
+	}
+	return x, nil
+}
+
+// Reset resets the Writer to an empty input.
+func (w *Writer) Reset() {
+	w.st, w.factors, w.err = newScanState(w.s, 0), Factors{}, nil
+}
+
+// Len returns the number of digits written so far.
+func (w *Writer) Len() int64 {
+	return int64(w.st.n)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+	"sync"
+)
+
+// hashFile returns the SHA-256 of the content of a file, read as a stream.
+func hashFile(filename string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(filename)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// findDuplicates hashes the files of a batch (--dedup) to find those with
+// the same content, so that each distinct content is counted only once.
+//
+// Files that can not be read are no duplicates: counting them reports the
+// error.
+//
+// Parameters:
+//   - files: Paths of the input files
+//   - workers: Maximum number of files hashed concurrently
+//
+// Returns:
+//   - []int: For each file, the index of the first file with the same
+//     content, or -1 if it is the first
+func findDuplicates(files []string, workers int) []int {
+	sums := make([][sha256.Size]byte, len(files))
+	ok := make([]bool, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var err error
+				sums[i], err = hashFile(files[i])
+				ok[i] = err == nil
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	dup := make([]int, len(files))
+	first := map[[sha256.Size]byte]int{}
+	for i := range files {
+		dup[i] = -1
+		if !ok[i] {
+			continue
+		}
+		if j, seen := first[sums[i]]; seen {
+			dup[i] = j
+		} else {
+			first[sums[i]] = i
+		}
+	}
+	return dup
+}
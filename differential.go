@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"task1/decodeways"
+)
+
+// verifyCount checks a count of the cluster algorithm against the classic
+// two-variable DP (see countMod), an independent algorithm.
+//
+// The DP can not afford the exact count, so both are compared modulo a random
+// prime of 63 bits, chosen anew on every run: a wrong count only passes if the
+// prime divides the error, which for a random prime is vanishingly unlikely
+// and can not be arranged by the input.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - s: The letter mapping scheme
+//   - x: The count of the cluster algorithm
+//
+// Returns:
+//   - error: An error if the counts disagree
+func verifyCount(p []byte, s *decodeways.Scheme, x *big.Int) error {
+	m, err := rand.Prime(rand.Reader, 63) // Below maxModulus
+	if err != nil {
+		return fmt.Errorf("choosing a prime: %w", err)
+	}
+	want, err := countMod(p, s, m.Uint64())
+	if err != nil {
+		return fmt.Errorf("verification DP: %w", err)
+	}
+	if got := new(big.Int).Mod(x, m).Uint64(); got != want {
+		return fmt.Errorf("verification failed: the cluster count is %d modulo %v, the DP gives %d", got, m, want)
+	}
+	return nil
+}
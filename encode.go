@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// encodeLetters encodes a string of letters into its digit form.
+//
+// This is the inverse of decoding: 'A' -> "1", 'B' -> "2", ..., 'Z' -> "26"
+// under the standard alphabet, or the codes of another alphabet (e.g. 'A' ->
+// "0" with --zero-based). Lower-case letters are accepted as well, and a
+// trailing newline is ignored.
+//
+// Parameters:
+//   - s: Byte slice containing the letters to encode
+//   - r: The code range of the alphabet
+//
+// Returns:
+//   - []byte: The digit encoding
+//   - error: An error if s is empty or contains a character other than a
+//     letter of the alphabet
+func encodeLetters(s []byte, r codeRange) ([]byte, error) {
+	s = bytes.TrimSuffix(bytes.TrimSuffix(s, []byte{0x0A}), []byte{0x0D})
+	if len(s) == 0 {
+		return nil, errors.New("empty string")
+	}
+	out := make([]byte, 0, len(s)*2)
+	for i, c := range s {
+		c &^= 0x20 // Upper case
+		if c < 'A' || c > 'Z' {
+			return nil, fmt.Errorf("encountered non-letter character at pos. %d", i)
+		}
+		v := r.lo + int(c-'A')
+		if v > r.hi {
+			return nil, fmt.Errorf("encountered letter %c outside the alphabet of %d letters at pos. %d", c, r.hi-r.lo+1, i)
+		}
+		out = strconv.AppendInt(out, int64(v), 10)
+	}
+	return out, nil
+}
+
+// runEncode implements the "encode" subcommand.
+//
+// It prints the digit encoding of the letters in the file (stdin if none is
+// given) and, with --count, the number of ways that encoding can be decoded
+// on a second line. The alphabet and count flags are those of the count mode.
+//
+// Usage: decode-ways encode [--count] [--format json] [<filename>]
+//
+// Returns:
+//   - int: The process exit code
+func runEncode(args []string) int {
+	fs := flag.NewFlagSet("encode", flag.ExitOnError)
+	count := fs.Bool("count", false, "also print the number of decode ways of the encoding")
+	countOpts := addCountFlags(fs)
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways encode [--count] [--format json] [<filename>]")
+		fmt.Fprintln(os.Stderr, "Example: echo HELLO | decode-ways encode --count")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 1
+	}
+	if countOpts.codes != "" {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: encode does not support --codes, whose codes are not numbered letters\n")))
+		return 1
+	}
+	r, err := newCodeRange(countOpts.alphabet, countOpts.zeroBased)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	c, err := newCounter(*countOpts)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+
+	var s []byte
+	if fs.NArg() == 0 {
+		s, err = io.ReadAll(os.Stdin)
+	} else {
+		s, err = readInput(fs.Arg(0), 0)
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error %v\n", trError(err))))
+		return exitIO
+	}
+	p, err := encodeLetters(s, r)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error encoding: %v\n", trError(err))))
+		return exitInvalid
+	}
+	rec := encodeRecord{Encoding: string(p)}
+	if !*count {
+		return formatAll(f, rec)
+	}
+	x, err := c.countInput(p)
+	if err != nil {
+		// The encoding is still printed, with the error on a line of its own
+		if code := formatAll(f, rec); code != 0 {
+			return code
+		}
+		fmt.Fprint(os.Stderr, "\n"+diagnostic(tr("Error decoding: %v\n", trError(err))))
+		return exitCode(err)
+	}
+	rec.Count = x
+	return formatAll(f, rec)
+}
+
+// encodeRecord is the record of an encoding, with its count if requested.
+type encodeRecord struct {
+	Encoding string `json:"encoding"`
+	Count    string `json:"count,omitempty"`
+}
+
+// plain implements plainRecord: the encoding and, with --count, the count on
+// a second line, both without a trailing newline like the count mode.
+func (r encodeRecord) plain() string {
+	if r.Count == "" {
+		return r.Encoding
+	}
+	return r.Encoding + "\n" + r.Count
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+)
+
+// Exit codes of the process, so that scripts can branch on the class of a
+// failure instead of parsing stderr. Usage errors and failures of no other
+// class exit with 1.
+const (
+	exitInvalid = 2 // The input can not be decoded
+	exitIO      = 3 // Reading an input or writing a result failed
+	exitTimeout = 4 // A time limit was exceeded
+	exitLimit   = 5 // A resource limit, like --max-memory, was exceeded
+	exitPartial = 6 // Some files of a batch failed, the others were counted
+)
+
+// limitError is the error of an exceeded resource limit.
+type limitError struct {
+	msg string
+}
+
+// Error implements the error interface.
+func (e *limitError) Error() string {
+	return e.msg
+}
+
+// exitCode returns the exit code of a failed count: the class of its error,
+// or exitInvalid, as counting mostly fails on inputs that can not be
+// decoded.
+//
+// Parameters:
+//   - err: The error of the count
+//
+// Returns:
+//   - int: The exit code
+func exitCode(err error) int {
+	var le *limitError
+	var pe *fs.PathError
+	var re *remoteError
+	switch {
+	case errors.As(err, &re):
+		return re.code
+	case errors.As(err, &le):
+		return exitLimit
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.As(err, &pe):
+		return exitIO
+	}
+	return exitInvalid
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"text/tabwriter"
+
+	"task1/decodeways"
+)
+
+// explainDigits is the number of digits of a cluster shown in full by
+// --explain; longer clusters are abbreviated.
+const explainDigits = 24
+
+// shortInt formats a number of --explain: in decimal if it is small, by its
+// bit length otherwise, as converting every running product to decimal would
+// take quadratic time.
+func shortInt(x *big.Int) string {
+	if x.BitLen() <= 128 {
+		return x.String()
+	}
+	return fmt.Sprintf("(%d bits)", x.BitLen())
+}
+
+// writeExplanation writes how the count of an input is derived (--explain):
+// one line per cluster with its offsets, digits, number of pairs, Fibonacci
+// factor and its value, and the running product, then the count. On a
+// terminal, the header, digits, factors and products are colored (see
+// setupColor); every cell gets a code of the same length, the other ones
+// ansiPlain, so the columns stay aligned.
+//
+// Parameters:
+//   - w: The writer
+//   - p: Byte slice containing the digit string
+//   - s: The letter mapping scheme
+//
+// Returns:
+//   - error: An error if the input is invalid
+func writeExplanation(w io.Writer, p []byte, s *decodeways.Scheme) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	c := colorStdout
+	product := big.NewInt(1)
+	n := 0
+	err := decodeways.EachCluster(p, s, func(cl decodeways.Cluster) {
+		digits := string(p[cl.Start:cl.End])
+		if len(digits) > explainDigits {
+			digits = digits[:explainDigits/2-2] + "...." + digits[len(digits)-explainDigits/2+2:]
+		}
+		if n == 0 {
+			for i, h := range []string{"start", "end", "digits", "pairs", "factor", "value", "product"} {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				fmt.Fprint(tw, paint(c, ansiBold, h))
+			}
+			fmt.Fprintln(tw)
+		}
+		f := decodeways.Fib(cl.Index)
+		product.Mul(product, f)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			paint(c, ansiPlain, fmt.Sprint(cl.Start)), paint(c, ansiPlain, fmt.Sprint(cl.End)),
+			paint(c, ansiCyan, digits), paint(c, ansiPlain, fmt.Sprint(cl.Size)),
+			paint(c, ansiYellow, fmt.Sprintf("F(%d)", cl.Index)), paint(c, ansiPlain, shortInt(f)),
+			paint(c, ansiGreen, shortInt(product)))
+		n++
+	})
+	if err != nil {
+		return err
+	}
+	tw.Flush()
+	if n == 0 {
+		fmt.Fprintln(w, "No clusters: every digit decodes in one way.")
+	}
+	fmt.Fprintf(w, "%s %v\n", paint(c, ansiBold, "Count:"), product)
+	return nil
+}
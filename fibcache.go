@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+// fibCacheMin is the smallest index persisted to the on-disk cache. Smaller
+// Fibonacci numbers are computed faster than a file can be read.
+const fibCacheMin = 1 << 20
+
+// fibCacheMagic starts every cache file.
+const fibCacheMagic = "DWFIB1"
+
+// diskFibCache is a decodeways.FibCache keeping one file per Fibonacci
+// number in a directory, bounded in size by --fib-cache-size. It is set up
+// from the --fib-cache flag.
+type diskFibCache struct {
+	dir *cacheDir
+}
+
+// defaultCacheDir returns the default directory of a cache, decode-ways/<name>
+// in the user cache directory ($XDG_CACHE_HOME or ~/.cache on Linux), or an
+// empty string if there is none.
+func defaultCacheDir(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "decode-ways", name)
+}
+
+// fibCacheName returns the name of the cache file holding F(n).
+func fibCacheName(n uint64) string {
+	return fmt.Sprintf("%d.bin", n)
+}
+
+// Load reads F(n) from the on-disk cache.
+//
+// A cache file is the magic string, n and the CRC-32 of the payload (both
+// little-endian), followed by the payload: F(n) as big-endian bytes.
+//
+// Parameters:
+//   - n: The index of the Fibonacci number
+//
+// Returns:
+//   - *big.Int: F(n), or nil if it is not cached or the file is damaged
+func (c diskFibCache) Load(n uint64) *big.Int {
+	if n < fibCacheMin {
+		return nil
+	}
+	data, err := c.dir.read(fibCacheName(n))
+	h := len(fibCacheMagic) + 12
+	if err != nil || len(data) <= h || !bytes.HasPrefix(data, []byte(fibCacheMagic)) {
+		return nil
+	}
+	payload := data[h:]
+	if binary.LittleEndian.Uint64(data[h-12:]) != n ||
+		binary.LittleEndian.Uint32(data[h-4:]) != crc32.ChecksumIEEE(payload) {
+		return nil
+	}
+	return new(big.Int).SetBytes(payload)
+}
+
+// Store writes F(n) to the on-disk cache, evicting the least recently used
+// numbers if it is over its size. The cache is best effort, so errors are
+// ignored.
+//
+// Parameters:
+//   - n: The index of the Fibonacci number
+//   - x: F(n)
+func (c diskFibCache) Store(n uint64, x *big.Int) {
+	if n < fibCacheMin {
+		return
+	}
+	payload := x.Bytes()
+	header := append([]byte(fibCacheMagic), make([]byte, 12)...)
+	binary.LittleEndian.PutUint64(header[len(fibCacheMagic):], n)
+	binary.LittleEndian.PutUint32(header[len(fibCacheMagic)+8:], crc32.ChecksumIEEE(payload))
+	c.dir.write(fibCacheName(n), header, payload)
+}
+
+// writeFileAtomic writes the concatenated chunks to a file, creating its
+// directory if needed. The data is written under a temporary name and
+// renamed, so concurrent runs never see a partial file.
+//
+// Parameters:
+//   - path: The path of the file
+//   - chunks: The contents of the file
+//
+// Returns:
+//   - error: An error if the file could not be written
+func writeFileAtomic(path string, chunks ...[]byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if _, err = tmp.Write(c); err != nil {
+			break
+		}
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
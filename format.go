@@ -0,0 +1,291 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// Formatter writes the records of a subcommand, e.g. a resultRecord per
+// count, in one output format (--format). Records are structs whose exported
+// fields, named by their json tags, are the data; a record also implements
+// plainRecord for the plain format.
+type Formatter interface {
+	// Format writes a record.
+	Format(v any) error
+	// Flush writes what is buffered, at the end of the output.
+	Flush() error
+}
+
+// formats are the constructors of the formatters of --format, by name. A
+// new format only needs an entry here; --format values containing "{{" are
+// templates (see templateFormatter).
+var formats = map[string]func(w io.Writer) Formatter{
+	"plain": func(w io.Writer) Formatter { return &plainFormatter{w: w} },
+	"json":  func(w io.Writer) Formatter { return &jsonFormatter{enc: json.NewEncoder(w)} },
+	"csv":   func(w io.Writer) Formatter { return &csvFormatter{w: csv.NewWriter(w)} },
+	"proto": func(w io.Writer) Formatter { return &protoFormatter{w: w} },
+	"gob":   func(w io.Writer) Formatter { return &gobFormatter{enc: gob.NewEncoder(w)} },
+	"raw":   func(w io.Writer) Formatter { return &rawFormatter{w: w} },
+}
+
+// addFormatFlag registers --format on a flag set.
+//
+// Parameters:
+//   - fs: The flag set
+//
+// Returns:
+//   - *string: The format, filled in when fs is parsed
+func addFormatFlag(fs *flag.FlagSet) *string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fs.String("format", "plain", fmt.Sprintf("output `format`: %s, or a Go template like '{{.File}} {{.Count}}'", strings.Join(names, ", ")))
+}
+
+// newFormatter returns the formatter of a --format value.
+//
+// Parameters:
+//   - w: The writer, usually stdout
+//   - format: The name of the format, or a template
+//
+// Returns:
+//   - Formatter: The formatter
+//   - error: An error if the format is unknown or the template invalid
+func newFormatter(w io.Writer, format string) (Formatter, error) {
+	if strings.Contains(format, "{{") {
+		t, err := template.New("format").Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("--format: %v", err)
+		}
+		return &templateFormatter{w: w, t: t}, nil
+	}
+	f, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("--format: unknown format %q", format)
+	}
+	return f(w), nil
+}
+
+// formatAll writes records and flushes the formatter, reporting a failure
+// on stderr.
+//
+// Parameters:
+//   - f: The formatter
+//   - records: The records
+//
+// Returns:
+//   - int: The process exit code, exitIO if the output failed
+func formatAll(f Formatter, records ...any) int {
+	for _, r := range records {
+		if err := f.Format(r); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+	}
+	if err := f.Flush(); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return exitIO
+	}
+	return 0
+}
+
+// runEach writes a record for every input file, for the subcommands that
+// examine inputs one by one without counting them (--check, --dry-run). The
+// problem of an input is also reported on stderr.
+//
+// Parameters:
+//   - files: Paths of the input files
+//   - maxLen: The limit of --max-len, 0 for none
+//   - f: The formatter of --format
+//   - each: Returns the record of an input and its problem, given the
+//     content of the file or, with p nil, the error reading it
+//
+// Returns:
+//   - int: The process exit code, that of the first file that failed (see
+//     exitCode)
+func runEach(files []string, maxLen int64, f Formatter, each func(name string, p []byte, err error) (any, error)) int {
+	code := 0
+	for _, name := range files {
+		p, err := readInput(name, maxLen)
+		r, err := each(name, p, err)
+		if err := f.Format(r); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+		switch {
+		case err == nil:
+		case p == nil:
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error %v\n", trError(err))))
+		case len(files) == 1:
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+		default:
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding %s: %v\n", name, trError(err))))
+		}
+		if err != nil && code == 0 {
+			code = exitCode(err)
+		}
+	}
+	if err := f.Flush(); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return exitIO
+	}
+	return code
+}
+
+// plainRecord is a record with a plain text form.
+type plainRecord interface {
+	// plain returns the text of the record, usually ending with a newline;
+	// records of errors, which are reported on stderr, return "".
+	plain() string
+}
+
+// plainTable is a record whose plain form is a row of a table, with tab
+// separated cells that are aligned over all records.
+type plainTable interface {
+	plainRecord
+	// plainHeader returns the header row of the table.
+	plainHeader() string
+}
+
+// plainFormatter writes records in their plain text form, the default.
+type plainFormatter struct {
+	w  io.Writer
+	tw *tabwriter.Writer // Aligns plainTable records
+}
+
+// Format implements Formatter.
+func (f *plainFormatter) Format(v any) error {
+	r, ok := v.(plainRecord)
+	if !ok {
+		_, err := fmt.Fprintln(f.w, v)
+		return err
+	}
+	if t, ok := v.(plainTable); ok {
+		if f.tw == nil {
+			f.tw = tabwriter.NewWriter(f.w, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprint(f.tw, t.plainHeader())
+		}
+		_, err := fmt.Fprint(f.tw, r.plain())
+		return err
+	}
+	_, err := fmt.Fprint(f.w, r.plain())
+	return err
+}
+
+// Flush implements Formatter.
+func (f *plainFormatter) Flush() error {
+	if f.tw != nil {
+		return f.tw.Flush()
+	}
+	return nil
+}
+
+// jsonFormatter writes records as JSON objects, one per line.
+type jsonFormatter struct {
+	enc *json.Encoder
+}
+
+// Format implements Formatter.
+func (f *jsonFormatter) Format(v any) error {
+	return f.enc.Encode(v)
+}
+
+// Flush implements Formatter.
+func (f *jsonFormatter) Flush() error {
+	return nil
+}
+
+// csvFormatter writes records as CSV rows, after a header with the json
+// names of the fields. Lists are joined with spaces.
+type csvFormatter struct {
+	w      *csv.Writer
+	header bool // Whether the header is written
+}
+
+// recordFields returns the exported fields of a record with their json names,
+// in the order of the struct.
+func recordFields(v reflect.Value) (names []string, values []reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		names = append(names, name)
+		values = append(values, v.Field(i))
+	}
+	return names, values
+}
+
+// Format implements Formatter.
+func (f *csvFormatter) Format(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: unsupported record %T", v)
+	}
+	names, values := recordFields(rv)
+	if !f.header {
+		f.w.Write(names)
+		f.header = true
+	}
+	row := make([]string, len(values))
+	for i, x := range values {
+		if x.Kind() == reflect.Slice && x.Type().Elem().Kind() == reflect.String {
+			row[i] = strings.Join(x.Interface().([]string), " ")
+		} else {
+			row[i] = fmt.Sprint(x.Interface())
+		}
+	}
+	f.w.Write(row)
+	f.w.Flush() // Every row as soon as it is known, like the other formats
+	return f.w.Error()
+}
+
+// Flush implements Formatter.
+func (f *csvFormatter) Flush() error {
+	f.w.Flush()
+	return f.w.Error()
+}
+
+// templateFormatter writes every record with a Go template, e.g.
+// '{{.File}}: {{.Count}}', followed by a newline. The template sees the Go
+// names of the fields.
+type templateFormatter struct {
+	w io.Writer
+	t *template.Template
+}
+
+// Format implements Formatter.
+func (f *templateFormatter) Format(v any) error {
+	if err := f.t.Execute(f.w, v); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(f.w)
+	return err
+}
+
+// Flush implements Formatter.
+func (f *templateFormatter) Flush() error {
+	return nil
+}
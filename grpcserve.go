@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"task1/decodeways"
+)
+
+// countServiceName is the full name of the service in proto/decodeways.proto.
+const countServiceName = "decodeways.v1.DecodeWays"
+
+// countService is the handler type of the gRPC service.
+type countService interface {
+	countStream(stream grpc.ServerStream) error
+	countResultStream(stream grpc.ServerStream) error
+}
+
+// countServiceDesc describes the gRPC service of proto/decodeways.proto. Its
+// messages are well-known wrapper types, or the Result message described by
+// resultDesc, so it is written by hand rather than generated.
+var countServiceDesc = grpc.ServiceDesc{
+	ServiceName: countServiceName,
+	HandlerType: (*countService)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName: "Count",
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(countService).countStream(stream)
+		},
+		ClientStreams: true,
+	}, {
+		StreamName: "CountResult",
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(countService).countResultStream(stream)
+		},
+		ClientStreams: true,
+	}},
+	Metadata: "proto/decodeways.proto",
+}
+
+// chunkReader reads the digit chunks of a client stream as one io.Reader.
+type chunkReader struct {
+	stream grpc.ServerStream
+	chunk  []byte // Unread rest of the current chunk
+	n      int64  // Number of bytes received
+	max    int64  // Maximum number of bytes, 0 for no limit
+}
+
+// Read implements io.Reader. Every chunk is received only when the previous
+// one is consumed, so gRPC flow control holds back a client that sends faster
+// than the input is scanned.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		m := new(wrapperspb.BytesValue)
+		if err := r.stream.RecvMsg(m); err != nil {
+			return 0, err // io.EOF once the client closes the stream
+		}
+		r.chunk = m.Value
+		r.n += int64(len(m.Value))
+		if r.max > 0 && r.n > r.max {
+			return 0, status.Errorf(codes.ResourceExhausted, "input larger than %d bytes", r.max)
+		}
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// countStream implements the Count method. Inputs over --max-input fail with
+// RESOURCE_EXHAUSTED and counts over --request-timeout with
+// DEADLINE_EXCEEDED.
+func (sv *server) countStream(stream grpc.ServerStream) error {
+	x, _, err := sv.awaitCount(stream)
+	if err != nil {
+		return err
+	}
+	return stream.SendMsg(wrapperspb.String(x))
+}
+
+// countResultStream implements the CountResult method: like Count, but an
+// invalid input is answered with a Result carrying the error.
+func (sv *server) countResultStream(stream grpc.ServerStream) error {
+	x, n, err := sv.awaitCount(stream)
+	r := resultRecord{Count: x, Length: int(n), Version: toolVersion(), Algorithm: sv.c.algorithm(nil)}
+	if err != nil {
+		st, _ := status.FromError(err)
+		if st.Code() != codes.InvalidArgument {
+			return err
+		}
+		r.Error = strings.TrimPrefix(st.Message(), "Error decoding: ")
+	}
+	return stream.SendMsg(resultMessage(r))
+}
+
+// awaitCount counts the input of a client stream within --request-timeout.
+//
+// Parameters:
+//   - stream: The client stream
+//
+// Returns:
+//   - string: The count in decimal
+//   - int64: The number of bytes received
+//   - error: A gRPC status error if the stream fails, the input is invalid
+//     (INVALID_ARGUMENT), too large (RESOURCE_EXHAUSTED), refused by a full
+//     compute queue (UNAVAILABLE) or not counted in time (DEADLINE_EXCEEDED)
+func (sv *server) awaitCount(stream grpc.ServerStream) (string, int64, error) {
+	ctx, cancel := sv.withTimeout(stream.Context())
+	defer cancel()
+	type result struct {
+		x   string
+		n   int64
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		start := time.Now()
+		r := &chunkReader{stream: stream, max: sv.maxInput}
+		x, err := sv.countChunks(ctx, r)
+		if status.Code(err) != codes.Unavailable {
+			sv.chargeCount(ctx, r.n, time.Since(start)) // Refused counts are free
+		}
+		done <- result{x, r.n, err}
+	}()
+	select {
+	case r := <-done:
+		return r.x, r.n, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", 0, status.Errorf(codes.DeadlineExceeded, "count not finished within %v", sv.timeout)
+		}
+		return "", 0, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// countChunks counts the input of a client stream.
+//
+// With the standard options the chunks are scanned as they arrive (see
+// decodeways.Scanner), so the input is never held in memory; a wildcard in a
+// streamed input is an error. Other options need the whole input, which is
+// then collected first and counted like a file.
+//
+// Parameters:
+//   - ctx: The context of the call, ending its wait for a compute worker
+//   - r: The reader of the stream
+//
+// Returns:
+//   - string: The count in decimal
+//   - error: A gRPC status error if the stream fails, the input is invalid,
+//     or the compute queue is full (UNAVAILABLE)
+func (sv *server) countChunks(ctx context.Context, r *chunkReader) (string, error) {
+	if sv.c.wide || sv.c.mod != 0 || sv.c.atLeast != nil || sv.c.lenient || sv.c.zeros != zerosError {
+		p, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		x, _, err := sv.count(ctx, p, true)
+		if errors.Is(err, errBusy) {
+			return "", status.Error(codes.Unavailable, err.Error())
+		} else if err != nil {
+			return "", status.Errorf(codes.InvalidArgument, "Error decoding: %v", err)
+		}
+		return x, nil
+	}
+	release, err := sv.compute.acquire(ctx, true)
+	if errors.Is(err, errBusy) {
+		return "", status.Error(codes.Unavailable, err.Error())
+	} else if err != nil {
+		return "", status.FromContextError(err).Err()
+	}
+	defer release()
+	n, err := decodeways.NewScanner(r, sv.c.s).Count()
+	recordMetrics(r.n, false, err)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return "", err // The stream failed rather than the input
+		}
+		return "", status.Errorf(codes.InvalidArgument, "Error decoding: %v", err)
+	}
+	return n.String(), nil
+}
+
+// newGRPCServer returns a gRPC server of the service, with the
+// authentication, rate limit and TLS of the server.
+func newGRPCServer(sv *server) *grpc.Server {
+	opts := []grpc.ServerOption{grpc.ChainStreamInterceptor(sv.authenticateStream, sv.limitStream, sv.meterStream)}
+	if sv.tls != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(sv.tls)))
+	}
+	g := grpc.NewServer(opts...)
+	g.RegisterService(&countServiceDesc, sv)
+	return g
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// isGRPC tells whether a request is a gRPC call: gRPC runs over HTTP/2 only,
+// with a Content-Type of application/grpc or application/grpc+<codec>.
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// grpcOrHTTP routes the gRPC calls of a connection to the gRPC server and
+// the other requests to the HTTP endpoints. gRPC calls skip the middleware
+// of routes: the interceptors of the gRPC server authenticate, limit and
+// meter them.
+func grpcOrHTTP(g *grpc.Server, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPC(r) {
+			g.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// newHTTPServer returns the HTTP server of --addr. It speaks HTTP/1.1 and
+// HTTP/2, negotiated with ALPN under TLS, and with cleartext the h2c of
+// internal meshes if enabled, where clients either know the server speaks
+// HTTP/2 or upgrade to it. Over HTTP/2 the streams of a client share one
+// connection, and the gRPC service is served on the same port as the REST
+// endpoints.
+//
+// The gRPC calls of the port get a gRPC server of their own rather than that
+// of --grpc-addr: stopping a gRPC server gracefully drains its connections,
+// which it can not do for calls handed to it by an HTTP server. The shutdown
+// of the HTTP server drains those instead.
+//
+// Parameters:
+//   - sv: The server
+//   - addr: The address to listen on
+//   - cleartext: Whether to accept h2c on a server without TLS
+//
+// Returns:
+//   - *http.Server: The HTTP server
+//   - error: An error if HTTP/2 can not be configured
+func newHTTPServer(sv *server, addr string, cleartext bool) (*http.Server, error) {
+	h2s := &http2.Server{}
+	h := grpcOrHTTP(newGRPCServer(sv), sv.routes())
+	if cleartext {
+		h = h2c.NewHandler(h, h2s)
+	}
+	// The configuration is cloned, as HTTP/2 adds its protocols to it
+	hs := &http.Server{Addr: addr, Handler: h, TLSConfig: sv.tls.Clone()}
+	// Also shuts the h2c connections down gracefully, which the HTTP server
+	// does not track
+	if err := http2.ConfigureServer(hs, h2s); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
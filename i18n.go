@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+
+	"task1/decodeways"
+)
+
+// localesEnv names the environment variable with a directory of additional
+// message catalogs (see loadCatalogs).
+const localesEnv = "DECODE_WAYS_LOCALES"
+
+// messages is the catalog of translated messages. English is the source
+// language: its messages are their own keys and need no entries.
+var messages = catalog.NewBuilder()
+
+// lang is the language of the messages, set by setLanguage.
+var lang = language.English
+
+// syntaxFormats are the formats of the messages of decodeways.SyntaxError
+// that contain characters of the input, so they can be translated too.
+var syntaxFormats = []string{
+	"encountered %c which can not be attached to %c",
+	"string starts with %c",
+}
+
+// addCatalog adds the translations of messages into a language.
+//
+// Parameters:
+//   - tag: The language
+//   - m: The translations, by English message (a format of fmt)
+func addCatalog(tag language.Tag, m map[string]string) {
+	for key, msg := range m {
+		messages.SetString(tag, key, msg)
+	}
+}
+
+// loadCatalogs loads the message catalogs of a directory: every file
+// "<language>.json" (e.g. "de.json", "pt-BR.json") holds a JSON object
+// mapping English messages to their translations, and adds to or overrides
+// the built-in ones.
+//
+// Parameters:
+//   - dir: The directory
+//
+// Returns:
+//   - error: An error if a catalog can not be read
+func loadCatalogs(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		tag, err := language.Parse(strings.TrimSuffix(filepath.Base(f), ".json"))
+		if err != nil {
+			return fmt.Errorf("catalog '%s': %w", f, err)
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		var m map[string]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("catalog '%s': %w", f, err)
+		}
+		addCatalog(tag, m)
+	}
+	return nil
+}
+
+// setLanguage selects the language of the messages: the closest one with a
+// catalog, or English.
+//
+// Parameters:
+//   - name: The language (--lang), e.g. "uk" or "uk_UA.UTF-8"; if empty,
+//     the locale of the environment ($LC_ALL, $LC_MESSAGES or $LANG)
+//
+// Returns:
+//   - error: An error if name is not a language
+func setLanguage(name string) error {
+	if name == "" {
+		for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if name = os.Getenv(v); name != "" {
+				break
+			}
+		}
+		if name == "" || name == "C" || name == "POSIX" {
+			lang = language.English
+			return nil
+		}
+	}
+	// Locales are "language_TERRITORY.codeset@modifier"
+	s, _, _ := strings.Cut(name, ".")
+	s, _, _ = strings.Cut(s, "@")
+	tag, err := language.Parse(strings.ReplaceAll(s, "_", "-"))
+	if err != nil {
+		return fmt.Errorf("unknown language %q", name)
+	}
+	supported := append([]language.Tag{language.English}, messages.Languages()...)
+	_, i, conf := language.NewMatcher(supported).Match(tag)
+	lang = language.English
+	if conf != language.No {
+		lang = supported[i]
+	}
+	return nil
+}
+
+// rawText collects the text of a catalog message without formatting it.
+type rawText struct {
+	strings.Builder
+}
+
+// Render implements catalog.Renderer.
+func (r *rawText) Render(s string) {
+	r.WriteString(s)
+}
+
+// Arg implements catalog.Renderer. The messages are formatted by fmt, so
+// they get no arguments.
+func (r *rawText) Arg(int) interface{} {
+	return nil
+}
+
+// tr formats a message in the selected language, like fmt.Sprintf. Messages
+// without a translation are formatted in English.
+//
+// The translations are only looked up in the catalog and formatted with fmt,
+// not with a message.Printer, whose localized number formatting would turn
+// positions and counts into "5,000,001".
+//
+// Parameters:
+//   - format: The English message
+//   - args: The arguments of the format
+//
+// Returns:
+//   - string: The formatted message
+func tr(format string, args ...any) string {
+	if lang != language.English {
+		var r rawText
+		if messages.Context(lang, &r).Execute(format) == nil {
+			format = r.String()
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// trError returns the message of an error in the selected language: the
+// messages of the decodeways.SyntaxError values in it are translated, the
+// rest is kept.
+//
+// Parameters:
+//   - err: The error
+//
+// Returns:
+//   - string: The message
+func trError(err error) string {
+	s := err.Error()
+	if lang == language.English {
+		return s
+	}
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *decodeways.SyntaxError:
+			s = strings.Replace(s, e.Error(), tr("%s at pos. %d", trSyntax(e.Msg), e.Pos), 1)
+		case interface{ Unwrap() []error }:
+			for _, u := range e.Unwrap() {
+				walk(u)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return s
+}
+
+// trSyntax translates the message of a decodeways.SyntaxError.
+func trSyntax(msg string) string {
+	for _, f := range syntaxFormats {
+		k := strings.Count(f, "%c")
+		c := make([]rune, k)
+		ptrs, vals := make([]any, k), make([]any, k)
+		for i := range c {
+			ptrs[i] = &c[i]
+		}
+		if n, _ := fmt.Sscanf(msg, f, ptrs...); n != k {
+			continue
+		}
+		for i := range c {
+			vals[i] = c[i]
+		}
+		if fmt.Sprintf(f, vals...) == msg {
+			return tr(f, vals...)
+		}
+	}
+	return tr(msg)
+}
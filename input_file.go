@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build nommap || tinygo
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// readInput reads the whole content of the named file.
+//
+// This is the fallback for TinyGo and platforms without mmap support, selected
+// with the nommap build tag: the file is read with plain file I/O, so
+// golang.org/x/exp/mmap is not compiled in.
+//
+// An http:// or https:// URL is downloaded instead, see fetchURL.
+//
+// Parameters:
+//   - filename: Path to the input file
+//   - limit: Maximum length of the input in bytes (--max-len), 0 for none
+//
+// Returns:
+//   - []byte: The file content
+//   - error: An error if the file can not be opened or read, or is longer
+//     than limit (checked before the content is allocated, as far as the
+//     size of the file is known)
+func readInput(filename string, limit int64) ([]byte, error) {
+	if isURL(filename) {
+		return fetchURL(filename, limit)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("opening file '%s': %w", filename, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit > 0 {
+		if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+			if err := checkInputLen(fi.Size(), limit); err != nil {
+				return nil, fmt.Errorf("file '%s': %w", filename, err)
+			}
+		}
+		r = io.LimitReader(f, limit+1) // Pipes and devices have no size
+	}
+	p, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading file '%s': %w", filename, err)
+	}
+	if limit > 0 && int64(len(p)) > limit {
+		return nil, fmt.Errorf("file '%s': %w", filename, &limitError{fmt.Sprintf("input is longer than the --max-len limit of %s", formatSize(limit))})
+	}
+	return p, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build !nommap && !tinygo
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/mmap"
+)
+
+// readInput reads the whole content of the named file.
+//
+// The file is read using memory-mapped I/O for efficient handling of large files.
+// Builds with the nommap tag (and TinyGo builds) read it with plain file I/O
+// instead, see input_file.go.
+//
+// An http:// or https:// URL is downloaded instead, see fetchURL.
+//
+// Parameters:
+//   - filename: Path to the input file
+//   - limit: Maximum length of the input in bytes (--max-len), 0 for none
+//
+// Returns:
+//   - []byte: The file content
+//   - error: An error if the file can not be opened or read, or is longer
+//     than limit (checked before the content is allocated)
+func readInput(filename string, limit int64) ([]byte, error) {
+	if isURL(filename) {
+		return fetchURL(filename, limit)
+	}
+	// Open file using memory-mapped I/O for efficient reading
+	r, err := mmap.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("opening file '%s': %w", filename, err)
+	}
+	defer r.Close()
+	if err := checkInputLen(int64(r.Len()), limit); err != nil {
+		return nil, fmt.Errorf("file '%s': %w", filename, err)
+	}
+
+	// Read entire file content into memory
+	p := make([]byte, r.Len())
+	if _, err = r.ReadAt(p, 0); err != nil {
+		return nil, fmt.Errorf("reading file '%s': %w", filename, err)
+	}
+	return p, nil
+}
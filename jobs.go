@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job states.
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// maxJobAttempts is the number of times a job is started before it is
+// failed: a job that keeps crashing the server is not retried forever.
+const maxJobAttempts = 3
+
+// errQueueFull is the error of a job submitted to a full queue.
+var errQueueFull = errors.New("job queue full")
+
+// job is an asynchronous count, as returned by GET /v1/jobs/{id}.
+type job struct {
+	ID       string     `json:"id"`
+	Status   string     `json:"status"` // jobQueued, jobRunning, jobDone or jobFailed
+	Bytes    int64      `json:"bytes"`
+	Count    string     `json:"count,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Attempts int        `json:"attempts,omitempty"` // Number of times the job was started
+	Tenant   string     `json:"tenant,omitempty"`   // SHA-256 of the API key in hex, charged for the count
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// jobQueue runs counts in the background for clients that should not hold a
+// connection open until a huge input is counted. The uploaded inputs are
+// spooled to files of a directory, so the queue holds no input in memory
+// until a worker counts it.
+//
+// Every change of a job is written to the journal of the directory before it
+// takes effect (see jobJournal), so the queue survives a restart of the
+// server: the jobs that were queued are queued again, those that were
+// running are started again, and the results of the finished ones can still
+// be polled.
+type jobQueue struct {
+	dir     string        // Directory of the spooled inputs and the journal
+	maxSize int64         // Maximum input size in bytes, 0 for no limit
+	ttl     time.Duration // Time finished jobs are kept
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	queue   chan string // IDs of the queued jobs
+	journal *jobJournal
+}
+
+// newJobQueue returns the job queue of a directory, with the jobs of its
+// journal recovered: queued and interrupted jobs are queued again (failed
+// once they were started maxJobAttempts times), finished ones are kept until
+// their TTL. Spooled inputs without a job, left by uploads that never
+// completed, are removed.
+//
+// Parameters:
+//   - dir: Directory of the spooled inputs and the journal, created if needed
+//   - capacity: Maximum number of queued jobs
+//   - maxSize: Maximum input size in bytes, 0 for no limit
+//   - ttl: Time finished jobs are kept before they are forgotten
+//
+// Returns:
+//   - *jobQueue: The queue
+//   - error: An error if the directory or the journal can not be used
+func newJobQueue(dir string, capacity int, maxSize int64, ttl time.Duration) (*jobQueue, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	journal, jobs, err := openJobJournal(dir)
+	if err != nil {
+		return nil, err
+	}
+	q := &jobQueue{dir: dir, maxSize: maxSize, ttl: ttl, jobs: map[string]*job{}, journal: journal}
+	var pending, kept []job
+	for _, j := range jobs {
+		if j.Status == jobQueued || j.Status == jobRunning {
+			if _, err := os.Stat(q.inputPath(j.ID)); err != nil {
+				j = failJob(j, "input lost in a restart of the server")
+			} else if j.Status == jobRunning && j.Attempts >= maxJobAttempts {
+				j = failJob(j, fmt.Sprintf("interrupted %d times by a restart of the server", j.Attempts))
+			} else {
+				j.Status, j.Started = jobQueued, nil
+				pending = append(pending, j)
+			}
+		}
+		if j.Finished != nil && time.Since(*j.Finished) > ttl {
+			continue // Expired
+		}
+		kept = append(kept, j)
+	}
+	if err := journal.compact(kept); err != nil {
+		journal.Close()
+		return nil, err
+	}
+	q.queue = make(chan string, max(capacity, len(pending)))
+	for i := range kept {
+		q.jobs[kept[i].ID] = &kept[i]
+	}
+	for _, j := range pending {
+		q.queue <- j.ID
+	}
+	names, _ := filepath.Glob(filepath.Join(dir, "*.input"))
+	for _, name := range names {
+		if j, ok := q.jobs[strings.TrimSuffix(filepath.Base(name), ".input")]; !ok || j.Finished != nil {
+			os.Remove(name)
+		}
+	}
+	if len(kept) > 0 {
+		slog.Info("jobs recovered", "queued", len(pending), "finished", len(kept)-len(pending))
+	}
+	return q, nil
+}
+
+// failJob returns a job failed with an error at the current time.
+func failJob(j job, msg string) job {
+	now := time.Now().UTC()
+	j.Status, j.Error, j.Finished = jobFailed, msg, &now
+	return j
+}
+
+// Close closes the journal of the queue.
+func (q *jobQueue) Close() error {
+	return q.journal.Close()
+}
+
+// inputPath returns the path of the spooled input of a job.
+func (q *jobQueue) inputPath(id string) string {
+	return filepath.Join(q.dir, id+".input")
+}
+
+// submit spools an input and queues its job.
+//
+// Parameters:
+//   - r: The input
+//   - tenant: The tenant charged for the count (see tenantHex), "" for none
+//
+// Returns:
+//   - job: The queued job
+//   - error: A limitError if the input is too large, errQueueFull, or an
+//     error if the input can not be received or spooled
+func (q *jobQueue) submit(r io.Reader, tenant string) (job, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return job{}, err
+	}
+	id := hex.EncodeToString(b[:])
+	f, err := os.OpenFile(q.inputPath(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return job{}, err
+	}
+	if q.maxSize > 0 {
+		r = io.LimitReader(r, q.maxSize+1)
+	}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Sync() // On disk before the journal refers to it
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && q.maxSize > 0 && n > q.maxSize {
+		err = &limitError{fmt.Sprintf("input larger than %d bytes", q.maxSize)}
+	}
+	if err != nil {
+		os.Remove(q.inputPath(id))
+		return job{}, err
+	}
+
+	j := &job{ID: id, Status: jobQueued, Bytes: n, Tenant: tenant, Created: time.Now().UTC()}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.queue) == cap(q.queue) {
+		os.Remove(q.inputPath(id))
+		return job{}, errQueueFull
+	}
+	if err := q.journal.append(*j); err != nil {
+		os.Remove(q.inputPath(id))
+		return job{}, err
+	}
+	q.jobs[id] = j
+	q.queue <- id // Does not block: only submit sends, under the lock
+	return *j, nil
+}
+
+// get returns a job by ID.
+func (q *jobQueue) get(id string) (job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return job{}, false
+	}
+	return *j, true
+}
+
+// update changes a job under the lock, and journals the change.
+func (q *jobQueue) update(id string, f func(j *job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j, ok := q.jobs[id]; ok {
+		f(j)
+		if err := q.journal.append(*j); err != nil {
+			slog.Error("journaling job failed", "id", id, "err", err)
+		}
+	}
+}
+
+// work counts the queued jobs one after another until the server shuts
+// down. Several workers may run at once.
+func (q *jobQueue) work(sv *server) {
+	for {
+		select {
+		case <-sv.closing:
+			return
+		case id := <-q.queue:
+			q.run(sv, id)
+		}
+	}
+}
+
+// run counts a job and records its result.
+func (q *jobQueue) run(sv *server, id string) {
+	start := time.Now().UTC()
+	tenant := ""
+	q.update(id, func(j *job) {
+		j.Status, j.Started, j.Attempts = jobRunning, &start, j.Attempts+1
+		tenant = j.Tenant
+	})
+	p, err := readInput(q.inputPath(id), 0)
+	x := ""
+	if err == nil {
+		x, _, err = sv.count(context.Background(), p, false) // Jobs wait in their own queue
+		sv.chargeCount(withTenantHex(context.Background(), tenant), int64(len(p)), time.Since(start))
+	}
+	end := time.Now().UTC()
+	q.update(id, func(j *job) {
+		j.Finished = &end
+		if err != nil {
+			j.Status = jobFailed
+			j.Error, _, _ = strings.Cut(err.Error(), "\n") // One line, without the snippet
+			return
+		}
+		j.Status, j.Count = jobDone, x
+	})
+	os.Remove(q.inputPath(id)) // Only once the result is journaled
+	slog.Info("job finished", "id", id, "bytes", len(p), "err", err, "duration", end.Sub(start))
+}
+
+// expire forgets the jobs finished longer than the TTL ago, every minute
+// until the server shuts down, and compacts the journal once most of its
+// lines are outdated.
+func (q *jobQueue) expire(closing <-chan struct{}) {
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+	for {
+		select {
+		case <-closing:
+			return
+		case now := <-t.C:
+			q.mu.Lock()
+			for id, j := range q.jobs {
+				if j.Finished != nil && now.Sub(*j.Finished) > q.ttl {
+					delete(q.jobs, id)
+				}
+			}
+			if q.journal.records > 2*len(q.jobs)+100 {
+				jobs := make([]job, 0, len(q.jobs))
+				for _, j := range q.jobs {
+					jobs = append(jobs, *j)
+				}
+				if err := q.journal.compact(jobs); err != nil {
+					slog.Error("compacting the job journal failed", "err", err)
+				}
+			}
+			q.mu.Unlock()
+		}
+	}
+}
+
+// handleSubmitJob implements POST /v1/jobs: the request body is the digit
+// string, spooled to disk and counted in the background. It is answered with
+// 202 and the job, whose URL is in the Location header; inputs over
+// --max-job-input get 413, and a full queue 503.
+func (sv *server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	j, err := sv.jobs.submit(r.Body, tenantHex(r.Context()))
+	var le *limitError
+	switch {
+	case errors.As(err, &le):
+		writeError(w, http.StatusRequestEntityTooLarge, le.msg)
+		return
+	case errors.Is(err, errQueueFull):
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		slog.Error("spooling job failed", "err", err)
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Error reading request: %v", err))
+		return
+	}
+	slog.Info("job queued", "id", j.ID, "bytes", j.Bytes)
+	w.Header().Set("Location", "/v1/jobs/"+j.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(j)
+}
+
+// handleJob implements GET /v1/jobs/{id}: the status of a job, with its
+// count or error once it is finished, or 404 for unknown and expired jobs.
+func (sv *server) handleJob(w http.ResponseWriter, r *http.Request) {
+	j, ok := sv.jobs.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such job")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(j)
+}
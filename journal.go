@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// journalName is the name of the job journal in the jobs directory.
+const journalName = "jobs.journal"
+
+// jobJournal is the write-ahead journal of a job queue: every change of a
+// job is appended as a JSON line holding the whole job, and synced to disk
+// before it takes effect, so that the last line of every job tells its state
+// after a crash.
+type jobJournal struct {
+	path    string
+	f       *os.File
+	records int // Lines written since the journal was last compacted
+}
+
+// openJobJournal opens the journal of a jobs directory and replays it.
+//
+// Parameters:
+//   - dir: The jobs directory
+//
+// Returns:
+//   - *jobJournal: The journal, opened for appending
+//   - []job: The last state of every job of the journal, oldest first
+//   - error: An error if the journal can not be read or opened
+func openJobJournal(dir string) (*jobJournal, []job, error) {
+	path := filepath.Join(dir, journalName)
+	jobs, err := replayJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &jobJournal{path: path, f: f, records: len(jobs)}, jobs, nil
+}
+
+// replayJournal reads the last state of every job of a journal. A last line
+// cut off by a crash is ignored, as its change never took effect.
+func replayJournal(path string) ([]job, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	byID := map[string]job{}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break // An incomplete last line, or none
+		} else if err != nil {
+			return nil, err
+		}
+		var j job
+		if json.Unmarshal(line, &j) == nil && j.ID != "" {
+			byID[j.ID] = j
+		}
+	}
+	jobs := make([]job, 0, len(byID))
+	for _, j := range byID {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].Created.Before(jobs[b].Created) })
+	return jobs, nil
+}
+
+// append records the new state of a job, synced to disk.
+func (jl *jobJournal) append(j job) error {
+	line, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	if _, err := jl.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	jl.records++
+	return jl.f.Sync()
+}
+
+// compact rewrites the journal with a line per job, dropping the earlier
+// states and the forgotten jobs. The new journal replaces the old one
+// atomically, so a crash leaves one or the other.
+func (jl *jobJournal) compact(jobs []job) error {
+	tmp := jl.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, j := range jobs {
+		if err = enc.Encode(j); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, jl.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	nf, err := os.OpenFile(jl.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	jl.f.Close()
+	jl.f, jl.records = nf, len(jobs)
+	return nil
+}
+
+// Close closes the journal.
+func (jl *jobJournal) Close() error {
+	return jl.f.Close()
+}
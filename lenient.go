@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"task1/decodeways"
+)
+
+// maxLenientWarnings is the number of skipped places --lenient warns about
+// one by one; the rest are summed up in a single warning.
+const maxLenientWarnings = 10
+
+// digitRuns splits an input into its maximal runs of digits, warning on
+// stderr about the non-digit characters between them.
+//
+// Parameters:
+//   - p: The input
+//
+// Returns:
+//   - [][2]int: The start and end offsets of every run
+func digitRuns(p []byte) [][2]int {
+	var runs [][2]int
+	places, skipped := 0, 0
+	for i := 0; i < len(p); {
+		j := i
+		for j < len(p) && p[j] >= 0x30 && p[j] <= 0x39 {
+			j++
+		}
+		if j > i {
+			runs = append(runs, [2]int{i, j})
+		}
+		k := j
+		for k < len(p) && (p[k] < 0x30 || p[k] > 0x39) {
+			k++
+		}
+		if k > j {
+			switch {
+			case places >= maxLenientWarnings:
+			case k-j <= 8:
+				fmt.Fprint(os.Stderr, diagnostic(fmt.Sprintf("Warning: skipped %q at pos. %d\n", p[j:k], j)))
+			default:
+				fmt.Fprint(os.Stderr, diagnostic(fmt.Sprintf("Warning: skipped %d non-digit characters at pos. %d\n", k-j, j)))
+			}
+			places++
+			skipped += k - j
+		}
+		i = k
+	}
+	if places > maxLenientWarnings {
+		fmt.Fprint(os.Stderr, diagnostic(fmt.Sprintf("Warning: skipped %d non-digit characters in %d places in total\n", skipped, places)))
+	}
+	return runs
+}
+
+// countRuns counts an input made of independent runs: the non-digit
+// characters skipped by --lenient and the standalone zeros of --zeros split
+// are hard boundaries, so the count is the product of the counts of the runs
+// between them. Invalid digits, like a '0' that can not be attached, are
+// still errors unless --zeros split.
+//
+// The clusters of all runs are collected into one set of factors and
+// multiplied once; the DPs count run by run.
+//
+// Parameters:
+//   - p: The input
+//   - count: The count of a run, used unless dp is false
+//   - dp: Whether count is a DP rather than the cluster scan
+//
+// Returns:
+//   - *big.Int: The product of the counts of the runs
+//   - error: An error if a run is invalid or there are no digits
+func (c *counter) countRuns(p []byte, count func(p []byte) (*big.Int, error), dp bool) (*big.Int, error) {
+	runs := [][2]int{{0, len(p)}}
+	if c.lenient {
+		runs = digitRuns(p)
+	}
+	if c.zeros == zerosSplit {
+		runs = splitStandalone(p, runs, c.s)
+	}
+	if len(runs) == 0 {
+		return nil, errors.New("no digits in the input")
+	}
+	if !dp {
+		start := time.Now()
+		factors := decodeways.Factors{}
+		for _, r := range runs {
+			if err := decodeways.ScanClusters(p[r[0]:r[1]], r[0], c.s, factors); err != nil {
+				return nil, err
+			}
+		}
+		timings.record("scan", start)
+		if err := checkMemory(memoryEstimate(factors, len(p))); err != nil {
+			return nil, err
+		}
+		defer timings.record("multiply", time.Now())
+		return factors.Product(), nil
+	}
+
+	x := big.NewInt(1)
+	for _, r := range runs {
+		y, err := count(p[r[0]:r[1]])
+		if err != nil {
+			// Positions of the DPs are relative to the run
+			var se *decodeways.SyntaxError
+			if errors.As(err, &se) {
+				se.Pos += r[0]
+			}
+			return nil, err
+		}
+		x.Mul(x, y)
+		if c.mod != 0 {
+			x.Mod(x, new(big.Int).SetUint64(c.mod))
+		}
+	}
+	return x, nil
+}
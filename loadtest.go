@@ -0,0 +1,270 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// loadtestReport is the report of a load test: what was sent, what came
+// back, and the latency percentiles of the answered requests.
+type loadtestReport struct {
+	Target    string  `json:"target"`
+	Seconds   float64 `json:"seconds"`
+	Sent      int     `json:"sent"`
+	OK        int     `json:"ok"`
+	Failed    int     `json:"failed"`  // Errors and non-200 answers
+	Dropped   int     `json:"dropped"` // Not sent, all --concurrency requests were in flight
+	CacheHits int     `json:"cache_hits"`
+	QPS       float64 `json:"qps"`  // Answered requests per second
+	MBPerSec  float64 `json:"mb_s"` // Input bytes of the answered requests per second
+	P50       float64 `json:"p50_ms"`
+	P90       float64 `json:"p90_ms"`
+	P99       float64 `json:"p99_ms"`
+	Max       float64 `json:"max_ms"`
+}
+
+// plain implements plainRecord.
+func (r loadtestReport) plain() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "target:    %s\n", r.Target)
+	fmt.Fprintf(&b, "requests:  %d sent, %d ok, %d failed, %d dropped, %d cache hits\n", r.Sent, r.OK, r.Failed, r.Dropped, r.CacheHits)
+	fmt.Fprintf(&b, "rate:      %.1f req/s, %.2f MB/s over %.1fs\n", r.QPS, r.MBPerSec, r.Seconds)
+	fmt.Fprintf(&b, "latency:   p50 %.2fms, p90 %.2fms, p99 %.2fms, max %.2fms\n", r.P50, r.P90, r.P99, r.Max)
+	return b.String()
+}
+
+// parseSizeRange parses a range of input sizes such as "1K-1M", or a single
+// size such as "64K", with the suffixes of parseSize.
+//
+// Parameters:
+//   - s: The range
+//
+// Returns:
+//   - int64, int64: The smallest and the largest size
+//   - error: An error if s is not a range of sizes
+func parseSizeRange(s string) (int64, int64, error) {
+	a, b, ok := strings.Cut(s, "-")
+	lo, err := parseSize(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	hi := lo
+	if ok {
+		if hi, err = parseSize(b); err != nil {
+			return 0, 0, err
+		}
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return lo, hi, nil
+}
+
+// loadInputs hands out the synthetic inputs of a load test: windows of a
+// random digit string of the profile, at random offsets, so that every
+// request has other content and misses the caches of the server, without
+// generating megabytes per request. The sizes are log-uniform between lo
+// and hi, so every order of magnitude of the range gets as many requests.
+type loadInputs struct {
+	base   []byte
+	lo, hi int64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// newLoadInputs generates the digit string the inputs are taken from, long
+// enough for a million distinct windows of the largest size.
+func newLoadInputs(lo, hi int64, tokens []string) *loadInputs {
+	return &loadInputs{base: benchInput(hi+1<<20, tokens, 1), lo: lo, hi: hi, rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// next returns the next input.
+func (l *loadInputs) next() []byte {
+	l.mu.Lock()
+	n := int64(math.Round(math.Exp(math.Log(float64(l.lo)) + l.rnd.Float64()*math.Log(float64(l.hi)/float64(l.lo)))))
+	off := l.rnd.Int63n(int64(len(l.base)) - n)
+	l.mu.Unlock()
+	for off > 0 && l.base[off] == 0x30 {
+		off-- // The '0' of a 10 or 20 token can not start an input
+	}
+	return l.base[off : off+n]
+}
+
+// percentile returns the p-th percentile of sorted latencies in
+// milliseconds, 0 for none.
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return float64(sorted[max(i, 0)]) / float64(time.Millisecond)
+}
+
+// runLoadtest implements the "loadtest" subcommand: it drives a server
+// started with "decode-ways serve" with synthetic inputs at a fixed rate and
+// reports the latency percentiles, for capacity planning without external
+// tools.
+//
+// The requests are sent at --qps whether or not the earlier ones were
+// answered (an open loop, like real clients), so a saturated server shows in
+// the latencies rather than in a lower rate; requests that would exceed
+// --concurrency in flight are dropped and counted.
+//
+// Usage: decode-ways loadtest --target http://host:8080 [--qps 500] [--size 1K-1M] [--duration 30s]
+//
+// Returns:
+//   - int: The process exit code
+func runLoadtest(args []string) int {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	target := fs.String("target", "", "`URL` of the server, e.g. http://localhost:8080")
+	qps := fs.Float64("qps", 100, "`requests` sent per second")
+	sizes := fs.String("size", "1K-64K", "`range` of input sizes, e.g. 1K-1M, spread log-uniformly")
+	profile := fs.String("profile", "dense", "input `profile` of bench: dense, sparse or zeros")
+	duration := fs.Duration("duration", 10*time.Second, "how long to send requests")
+	concurrency := fs.Int("concurrency", 256, "maximum number of `requests` in flight")
+	timeout := fs.Duration("timeout", time.Minute, "give up waiting for an answer after this time")
+	apiKey := fs.String("api-key", os.Getenv("DECODE_WAYS_API_KEY"), "API `key` sent as a bearer token (default $DECODE_WAYS_API_KEY)")
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways loadtest --target <url> [--qps 500] [--size 1K-1M] [--duration 30s]")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways loadtest --target http://localhost:8080 --qps 500 --size 1K-1M")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if *target == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+	lo, hi, err := parseSizeRange(*sizes)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --size: %v\n", trError(err))))
+		return 1
+	}
+	tokens, ok := benchProfiles[*profile]
+	if !ok {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: unknown profile %q\n", *profile)))
+		return 1
+	}
+	if *qps <= 0 || *concurrency < 1 {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --qps and --concurrency must be positive\n")))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	url := strings.TrimSuffix(*target, "/") + "/count"
+	inputs := newLoadInputs(lo, hi, tokens)
+	client := &http.Client{
+		Timeout:   *timeout,
+		Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency},
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		r         = loadtestReport{Target: *target} // Sent and Dropped are only counted by this goroutine
+		bytesOK   int64
+		wg        sync.WaitGroup
+	)
+	send := func(p []byte) {
+		defer wg.Done()
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(p))
+		if err != nil {
+			panic(err) // The URL is checked before the first request
+		}
+		if *apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+*apiKey)
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		elapsed := time.Since(start)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err != nil:
+			r.Failed++
+			if r.Failed == 1 {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			}
+		case resp.StatusCode != http.StatusOK:
+			r.Failed++
+			if r.Failed == 1 {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: %s answered %s\n", url, resp.Status)))
+			}
+		default:
+			r.OK++
+			bytesOK += int64(len(p))
+			latencies = append(latencies, elapsed)
+			if resp.Header.Get("X-Cache") == "hit" {
+				r.CacheHits++
+			}
+		}
+	}
+
+	if _, err := http.NewRequest(http.MethodPost, url, nil); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --target: %v\n", trError(err))))
+		return 1
+	}
+	sem := make(chan struct{}, *concurrency)
+	tick := time.NewTicker(time.Duration(float64(time.Second) / *qps))
+	defer tick.Stop()
+	start := time.Now()
+	deadline := time.After(*duration)
+loop:
+	for {
+		select {
+		case <-deadline:
+			break loop
+		case <-tick.C:
+			select {
+			case sem <- struct{}{}:
+				r.Sent++
+				wg.Add(1)
+				go func(p []byte) {
+					defer func() { <-sem }()
+					send(p)
+				}(inputs.next())
+			default:
+				r.Dropped++
+			}
+		}
+	}
+	wg.Wait()
+	r.Seconds = time.Since(start).Seconds()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	r.QPS = float64(r.OK) / r.Seconds
+	r.MBPerSec = float64(bytesOK) / (1 << 20) / r.Seconds
+	r.P50, r.P90, r.P99 = percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99)
+	r.Max = percentile(latencies, 100)
+	if code := formatAll(f, r); code != 0 {
+		return code
+	}
+	if r.OK == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// logOptions are the options of the structured log on stderr.
+type logOptions struct {
+	level  string
+	format string
+}
+
+// addLogFlags registers --log-level and --log-format on a flag set.
+//
+// The events of a count (open, scan, multiply, result) are logged at level
+// info, the chunks of a long scan at level debug, so counts stay quiet by
+// default while the services, which default to info, log them.
+//
+// Parameters:
+//   - fs: The flag set
+//   - level: The default level
+//
+// Returns:
+//   - *logOptions: The options, filled in when fs is parsed
+func addLogFlags(fs *flag.FlagSet, level string) *logOptions {
+	o := &logOptions{}
+	fs.StringVar(&o.level, "log-level", level, "lowest `level` of the events logged on stderr: debug, info, warn or error")
+	fs.StringVar(&o.format, "log-format", "text", "`format` of the log: text or json")
+	return o
+}
+
+// setupLogging installs the default slog logger, writing to stderr.
+//
+// Parameters:
+//   - o: The log options
+//
+// Returns:
+//   - error: An error if the level or the format is unknown
+func setupLogging(o logOptions) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(o.level)); err != nil {
+		return fmt.Errorf("--log-level: unknown level %q (debug, info, warn or error)", o.level)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch o.format {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, opts)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, opts)))
+	default:
+		return fmt.Errorf("--log-format: unknown format %q (text or json)", o.format)
+	}
+	return nil
+}
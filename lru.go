@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruEntry is an entry of an lruCache.
+type lruEntry struct {
+	key, value string
+}
+
+// lruCache is a size-bounded least-recently-used cache of counts by key.
+//
+// The size of an entry is the length of its key plus its value, so a few huge
+// counts can not hold an unbounded amount of memory; when the bound is
+// exceeded, the least recently used entries are evicted. It is safe for
+// concurrent use, and a nil cache stores nothing.
+type lruCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+// newLRUCache returns an empty cache of up to maxSize bytes, or nil if
+// maxSize is not positive.
+func newLRUCache(maxSize int64) *lruCache {
+	if maxSize <= 0 {
+		return nil
+	}
+	return &lruCache{maxSize: maxSize, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get returns the value of a key and marks it as recently used.
+func (c *lruCache) get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// put stores a value, evicting the least recently used entries as needed.
+// Values larger than the whole cache are not stored.
+func (c *lruCache) put(key, value string) {
+	if c == nil {
+		return
+	}
+	n := int64(len(key) + len(value))
+	if n > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return // Counts of a key never change
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	c.size += n
+	for c.size > c.maxSize {
+		e := c.order.Back()
+		old := c.order.Remove(e).(*lruEntry)
+		delete(c.entries, old.key)
+		c.size -= int64(len(old.key) + len(old.value))
+	}
+}
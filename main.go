@@ -28,51 +28,21 @@ For example:
 The total number of combinations is the product of Fibonacci numbers for all clusters.
 
 Time Complexity: O(n) where n is the length of the input string
-Space Complexity: O(m) where m is the size of the largest cluster (for Fibonacci cache)
+Space Complexity: O(1) for the Fibonacci cache (bounded table plus fast doubling)
 */
 package main
 
 import (
-	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"os"
+	"time"
 
-	"golang.org/x/exp/mmap"
+	"task1/decodeways"
 )
 
-// f is the Fibonacci cache table storing precomputed Fibonacci numbers.
-// Initialized with F(0) = 0 and F(1) = 1 as base cases.
-// Values are computed on-demand by the fib() function and stored for reuse.
-var f = []*big.Int{big.NewInt(0), big.NewInt(1)}
-
-// maxFib stores the index of the maximum Fibonacci number currently cached.
-// Used to determine if we need to compute additional Fibonacci numbers.
-var maxFib uint64 = 2
-
-// fib calculates and returns the nth Fibonacci number using memoization.
-//
-// The function uses big.Int to handle arbitrarily large Fibonacci numbers.
-// For reference, F(93) = 12,200,160,415,121,876,738 is the largest Fibonacci
-// number that fits in int64.
-//
-// Parameters:
-//   - n: The index of the Fibonacci number to calculate (0-indexed)
-//
-// Returns:
-//   - *big.Int: The nth Fibonacci number
-//
-// Time Complexity: O(1) if cached, O(n - maxFib) if not cached
-func fib(n uint64) *big.Int {
-	// Expand the Fibonacci cache up to index n if needed
-	for ; maxFib <= n; maxFib++ {
-		c := big.Int{}
-		// F(n) = F(n-1) + F(n-2)
-		f = append(f, c.Add(f[maxFib-1], f[maxFib-2]))
-	}
-	return f[n]
-}
-
 // getPossibleCombinations calculates the number of ways to decode a digit string.
 //
 // The algorithm works by:
@@ -80,112 +50,495 @@ func fib(n uint64) *big.Int {
 //  2. Identifying "clusters" of digits that can be decoded multiple ways
 //  3. Multiplying the Fibonacci numbers corresponding to each cluster size
 //
+// The factors are collected first and multiplied at the end with a product
+// tree (see decodeways.Factors.Product), which is much faster than updating a
+// running product when there are many clusters.
+//
 // A cluster is a sequence of consecutive digits where each pair is in the range
 // 11-19 or 21-26. These are the only two-digit combinations that can validly
 // be decoded either as two separate letters or as one letter.
 //
+// A digit that is not a code on its own (the '0' of 10 and 20) must be attached
+// to the previous digit, which is then taken out of the cluster it ends.
+//
+// The code ranges are not hard-wired: they are described by a scheme, so
+// the same scan serves alternative mappings such as 'A' -> 0, ..., 'Z' -> 25.
+//
 // Parameters:
 //   - p: Byte slice containing the digit string to decode
+//   - s: The letter mapping scheme
 //
 // Returns:
 //   - *big.Int: The number of possible decodings
 //   - error: An error if the input is invalid
 //
 // Example:
-//   - "12" -> cluster size 1 -> F(3) = 2 ways
-//   - "226" -> cluster of "22" (size 1) -> F(3) = 2, then "6" separate -> 2 * 1 = 2... wait
-//     Actually "226": we have "2,2,6" which can be "2,26" or "22,6" or "2,2,6"
-//     Let me reconsider... "226" has a cluster "226" where 22 and 26 are valid pairs
-//     but we need to track this more carefully in the logic
-func getPossibleCombinations(p []byte) (*big.Int, error) {
-	clusterSize := uint64(0) // Current size of the cluster being processed
-	x := big.NewInt(1)       // Running product of Fibonacci numbers
-	a := p[0]                // Previous digit (for pair checking)
+//   - "12" -> cluster "12" (size 1) -> F(3) = 2 ways
+//   - "226" -> cluster "226" (size 2, as 22 and 26 are valid pairs) -> F(4) = 3 ways
+//   - "110" -> the last '1' is taken by "10", leaving "1" (size 0) -> F(2) = 1 way
+func getPossibleCombinations(p []byte, s *decodeways.Scheme) (*big.Int, error) {
+	start := time.Now()
+	factors := decodeways.Factors{}
+	if err := decodeways.ScanClusters(p, 0, s, factors); err != nil {
+		return big.NewInt(0), err
+	}
+	timings.record("scan", start)
+	if err := checkMemory(memoryEstimate(factors, len(p))); err != nil {
+		return big.NewInt(0), err
+	}
+	defer timings.record("multiply", time.Now())
+	return factors.Product(), nil
+}
+
+// main dispatches to the subcommand named by the first argument (see
+// commands). Without one, the arguments are those of the count subcommand,
+// so "decode-ways test2.txt" is "decode-ways count test2.txt".
+//
+// Usage:
+//   decode-ways [count] [flags] <filename>...
+//   decode-ways [count] [flags] --null < <records>
+//   decode-ways enumerate [--match <pattern>] [--limit <n>] <filename>
+//   decode-ways verify <filename> <letters>
+//   decode-ways compare [flags] <a> <b>
+//   decode-ways encode [--count] <filename>
+//   decode-ways construct --count <n>
+//   decode-ways gen [--size 1M] [--profile dense]
+//   decode-ways gen [--size 1M] --clusters <dist> [--zero-density 0.1]
+//   decode-ways gen --count <n> [--max-len 50] [--with-answers <file>]
+//   decode-ways bench [--sizes 1M,100M] [--profiles dense,sparse,zeros]
+//   decode-ways loadtest --target <url> [--qps 500] [--size 1K-1M]
+//   decode-ways selftest [-v]
+//   decode-ways serve [--addr :8080] [--cache-size 64M] [--grpc-addr :9090]
+//   decode-ways client --server <address> <filename>...
+//   decode-ways daemon --socket <path>
+//   decode-ways watchdir <dir> --done <dir>
+//   decode-ways completion bash|zsh|fish
+//
+// Example:
+//   decode-ways test2.txt
+//   decode-ways --best test.txt
+//   decode-ways verify test.txt ABKF
+//
+// Exit status (see exitcodes.go):
+//   0 success, 1 usage error or other failure, 2 invalid input,
+//   3 I/O error, 4 timeout, 5 resource limit exceeded, 6 some files of a
+//   batch failed
+func main() {
+	setupLogging(logOptions{level: "warn", format: "text"})
+	setupColor(false) // From the terminal and $NO_COLOR, until --no-color
+	if dir := os.Getenv(localesEnv); dir != "" {
+		if err := loadCatalogs(dir); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(fmt.Sprintf("Warning: %v\n", err)))
+		}
+	}
+	setLanguage("") // From the environment; an unknown locale leaves English
+	if len(os.Args) > 1 {
+		if os.Args[1] == "__flags" {
+			os.Exit(runFlags(os.Args[2:]))
+		}
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+	os.Exit(runCount(os.Args[1:]))
+}
+
+// runCount implements the "count" subcommand, which reads digit strings from
+// files and prints the number of decode ways, or one of the other reports
+// selected by the flags.
+//
+// Returns:
+//   - int: The process exit code
+func runCount(args []string) int {
+	fs := flag.NewFlagSet("count", flag.ExitOnError)
+	countOpts := addCountFlags(fs)
+	best := fs.Bool("best", false, "print the most probable decoding under the letter-frequency model")
+	top := fs.Int("top", 0, "print the `k` most probable decodings with their scores")
+	dag := fs.String("dag", "", "write the decoding DAG in `format` dot or json instead of the count")
+	match := fs.String("match", "", "count decodings matching a glob `pattern` (letters, '?' and '*')")
+	list := fs.Int("list", 0, "with --match, print up to `n` matching decodings instead of the count")
+	letterStats := fs.Bool("letter-stats", false, "print the expected occurrences of every letter in a random decoding")
+	entropy := fs.Bool("entropy", false, "print the entropy of the decoding distribution per choice point")
+	explain := fs.Bool("explain", false, "print every cluster with its Fibonacci factor and the running product")
+	clusterStats := fs.Bool("cluster-stats", false, "print a histogram of cluster sizes, the codes with a zero and the gaps between clusters")
+	lengths := fs.Bool("lengths", false, "print the number of decodings of every length")
+	workers := fs.Int("workers", 1, "number of `files` counted concurrently when several are given")
+	dedup := fs.Bool("dedup", false, "hash the files of a batch first and count each distinct content once, reusing the count for duplicates")
+	sqlitePath := fs.String("sqlite", "", "record every count in the SQLite database `file` (file, hash, count, time)")
+	fibCache := fs.String("fib-cache", defaultCacheDir("fib"), "`dir` caching huge Fibonacci numbers across runs (empty to disable)")
+	fibCacheSize := fs.String("fib-cache-size", "1G", "evict the least recently used numbers when --fib-cache exceeds `size` (0 for no limit)")
+	resultCacheDir := fs.String("result-cache", defaultCacheDir("results"), "`dir` caching counts by input hash (empty to disable)")
+	resultCacheSize := fs.String("result-cache-size", "256M", "evict the least recently used counts when --result-cache exceeds `size` (0 for no limit)")
+	warmup := fs.Uint64("warmup", 0, "precompute the Fibonacci numbers up to index `n` before reading the input")
+	stats := fs.Bool("stats", false, "report the time and throughput of every phase on stderr")
+	noProgress := fs.Bool("no-progress", false, "do not show a progress bar while scanning large inputs")
+	checkpointFile := fs.String("checkpoint", "", "periodically save the scan state to `file` so the count can be resumed")
+	checkpointInterval := fs.Duration("checkpoint-interval", time.Minute, "time between two checkpoints")
+	resume := fs.Bool("resume", false, "continue the count from the --checkpoint file")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile to `file`")
+	memProfile := fs.String("memprofile", "", "write a heap profile to `file` at exit")
+	traceFile := fs.String("trace", "", "write an execution trace to `file`")
+	maxMem := fs.String("max-memory", "", "fail fast if the estimated memory use exceeds `size` (e.g. 2G)")
+	crossCheck := fs.Bool("cross-check", false, fmt.Sprintf("also enumerate every decoding of inputs up to %d digits and compare the counts", crossCheckMax))
+	freqFile := fs.String("freq", "", "`file` with letter frequencies overriding the built-in English model")
+	format := addFormatFlag(fs)
+	reportFile := fs.String("report", "", "count the files as a batch and write a JSON `file` with the result of each and aggregate stats")
+	logResults := fs.String("log-results", "", "append a row per count (timestamp, file, hash, count, duration) to the CSV `file`")
+	timeout := fs.Duration("timeout", 0, "abort with exit code 4 if the computation takes longer than `duration` (0 for no limit)")
+	retries := fs.Int("retries", fetchPolicy.retries, "number of times a failed download of an http(s):// input is retried")
+	retryBackoff := fs.Duration("retry-backoff", fetchPolicy.backoff, "delay before the first retry of a download, doubled for every other")
+	null := fs.Bool("null", false, "count the NUL-separated digit strings of stdin (like xargs -0) instead of files, one result each")
+	dryRun := fs.Bool("dry-run", false, "only scan the inputs and print the plan of the count (segments, clusters, estimated result digits and memory)")
+	check := fs.Bool("check", false, "only validate the inputs, printing \"OK\" or the first problem, without counting")
+	logOpts := addLogFlags(fs, "warn")
+	noColor := fs.Bool("no-color", false, "do not color diagnostics, even on a terminal (also $NO_COLOR)")
+	langName := fs.String("lang", "", "`language` of the messages, e.g. uk (default from $LC_ALL, $LC_MESSAGES or $LANG)")
+	fs.Usage = func() { usage(fs) }
+	parseFlags(fs, args)
+	setupColor(*noColor)
+	if *langName != "" {
+		if err := setLanguage(*langName); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", err)))
+			exit(1)
+		}
+	}
+	if err := setupLogging(*logOpts); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		exit(1)
+	}
+	if err := startProfiling(*cpuProfile, *memProfile, *traceFile); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error %v\n", trError(err))))
+		exit(1)
+	}
+	defer stopProfiles()
+	watchStatusSignals()
+	defer watchTimeout(*timeout)()
+	showProgress = !*noProgress
+	if *stats {
+		timings = newPhaseTimer()
+		defer timings.report(os.Stderr)
+	}
+	if dir, err := openCacheDir(*fibCache, *fibCacheSize); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --fib-cache-size: %v\n", trError(err))))
+		exit(1)
+	} else if dir != nil {
+		decodeways.SetFibCache(diskFibCache{dir})
+	}
+	if dir, err := openCacheDir(*resultCacheDir, *resultCacheSize); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --result-cache-size: %v\n", trError(err))))
+		exit(1)
+	} else {
+		resultCache = dir
+	}
+	fetchPolicy = retryPolicy{retries: *retries, backoff: *retryBackoff}
+	if *maxMem != "" {
+		n, err := parseSize(*maxMem)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --max-memory: %v\n", trError(err))))
+			exit(1)
+		}
+		setMaxMemory(n)
+	}
+
+	if *resume && *checkpointFile == "" {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --resume needs --checkpoint\n")))
+		exit(1)
+	}
+
+	if *warmup > 0 {
+		decodeways.Precompute(*warmup)
+	}
+
+	// Check if filename argument is provided
+	if fs.NArg() < 1 && !*null {
+		fs.Usage()
+		exit(1)
+	}
+
+	c, err := newCounter(*countOpts)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		exit(1)
+	}
+
+	// The reports name the letters 'A'-'Z' of the codes 1-26 and weigh them
+	// by English letter frequencies, which other alphabets do not have
+	if (*dag != "" || *match != "" || *best || *top > 0 || *letterStats || *entropy || *lengths) && (c.wide || *c.s != decodeways.StandardScheme) {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --best, --top, --dag, --match, --letter-stats, --entropy and --lengths only support the alphabet 'A' = 1, ..., 'Z' = 26\n")))
+		exit(1)
+	}
+
+	if (*dag != "" || *explain || *clusterStats) && *format != "plain" {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --dag, --explain and --cluster-stats write text and do not support --format\n")))
+		exit(1)
+	}
 
-	// Validate first character: must be a digit 1-9 (no leading zero)
-	if a == 0x30 { // '0'
-		return big.NewInt(0), errors.New("string starts with 0")
-	} else if a < 0x31 || a > 0x39 { // Not '1'-'9'
-		return big.NewInt(0), errors.New("string starts with non-digit character")
+	rw, err := newResultWriter(os.Stdout, *format, fs, *logResults)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		exit(1)
+	}
+	if rw.binary && c.atLeast != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --at-least can not be combined with --format %s, which has no \"≥\"\n", *format)))
+		exit(1)
+	}
+	if *null {
+		if fs.NArg() > 0 || *check || *dryRun || *sqlitePath != "" || *dag != "" || *letterStats || *entropy || *lengths || *explain || *clusterStats || *match != "" || *best || *top > 0 {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --null reads the inputs from stdin and only supports counting, without files and --sqlite\n")))
+			exit(1)
+		}
+		if *reportFile != "" {
+			rw.report = newBatchReport(rw.flags)
+		}
+		code := runNull(c, os.Stdin, rw)
+		if rw.report != nil {
+			if err := rw.report.save(*reportFile); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: --report: %v\n", trError(err))))
+				if code == 0 {
+					code = exitIO
+				}
+			}
+		}
+		timings.report(os.Stderr)
+		exit(code)
+	}
+	if *check && *dryRun {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --check and --dry-run can not be combined\n")))
+		exit(1)
+	}
+	if *check {
+		return runCheck(c, fs.Args(), rw.f)
+	}
+	if *dryRun {
+		return runPlan(c, fs.Args(), rw.f)
 	}
 
-	// Process each subsequent digit
-	for i, b := range p[1:] {
-		// Validate that current character is a digit
-		if b < 0x30 || b > 0x39 { // Not '0'-'9'
-			return big.NewInt(0), fmt.Errorf("encountered non-digit character at pos. %d", i)
+	// Several files are counted as a batch, as are files recorded in a database
+	// or a report
+	if fs.NArg() > 1 || *sqlitePath != "" || *reportFile != "" {
+		if *dag != "" || *letterStats || *entropy || *lengths || *explain || *clusterStats || *match != "" || *best || *top > 0 {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: only counting supports several files, --sqlite and --report\n")))
+			exit(1)
+		}
+		if *workers > 1 {
+			showProgress = false // Concurrent bars would overwrite each other
+		}
+		var db *resultsDB
+		if *sqlitePath != "" {
+			if db, err = openResultsDB(*sqlitePath); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: opening %s: %v\n", *sqlitePath, trError(err))))
+				exit(1)
+			}
+		}
+		if *reportFile != "" {
+			rw.report = newBatchReport(rw.flags)
+		}
+		code := runBatch(c, fs.Args(), *workers, db, rw, *dedup)
+		if db != nil {
+			db.Close()
 		}
+		if rw.report != nil {
+			if err := rw.report.save(*reportFile); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: --report: %v\n", trError(err))))
+				if code == 0 {
+					code = exitIO
+				}
+			}
+		}
+		timings.report(os.Stderr)
+		exit(code)
+	}
 
-		// Check for invalid zero: '0' can only appear after '1' or '2' (forming 10 or 20)
-		if b == 0x30 && a != 0x31 && a != 0x32 {
-			return big.NewInt(0), fmt.Errorf("encountered 0 which can not be attached to %c at pos. %d", a, i)
+	filename := fs.Arg(0)
+
+	start := time.Now()
+	p, err := readInput(filename, c.maxLen)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error %v\n", trError(err))))
+		code := exitIO
+		if exitCode(err) == exitLimit {
+			code = exitLimit // --max-len
 		}
+		exit(code)
+	}
+	timings.record("read", start)
+	timings.addDigits(len(p))
+	slog.Info("open", "file", filename, "bytes", len(p))
+	if err := checkMemory(int64(len(p))); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: input: %v\n", trError(err))))
+		exit(exitLimit)
+	}
 
-		// Identify cluster boundaries
-		// A pair (a, b) is in a cluster if it forms 11-19 or 21-26
-		// Note: 10 and 20 are NOT in clusters as they have only one decoding
-		if (a == 0x31 && b > 0x30) || (a == 0x32 && b > 0x30 && b <= 0x36) {
-			// We are inside a cluster: the pair can be decoded in 2 ways
-			clusterSize++
-		} else if clusterSize > 0 {
-			// We've exited a cluster: multiply result by F(clusterSize + 2)
-			// The +2 offset is because a cluster of size 1 has F(3) = 2 ways
-			x.Mul(x, fib(clusterSize+2))
-			clusterSize = 0 // Reset cluster size
+	if *dag != "" {
+		if err := writeDAG(os.Stdout, p, *dag); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error writing DAG: %v\n", trError(err))))
+			exit(exitCode(err))
 		}
+		return 0
+	}
 
-		a = b // Move to next digit
+	if *explain {
+		if c.wide {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --explain only supports codes of up to two digits\n")))
+			exit(1)
+		}
+		if err := writeExplanation(os.Stdout, p, c.s); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(withLocation(err, p)))))
+			exit(exitCode(err))
+		}
+		return 0
 	}
 
-	// Handle the case where the string ends inside a cluster
-	if clusterSize > 0 {
-		x.Mul(x, fib(clusterSize+2))
+	if *clusterStats {
+		if c.wide {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --cluster-stats only supports codes of up to two digits\n")))
+			exit(1)
+		}
+		st, err := collectClusterStats(p, c.s)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(withLocation(err, p)))))
+			exit(exitCode(err))
+		}
+		st.write(os.Stdout, len(p))
+		return 0
 	}
 
-	return x, nil
-}
+	if *letterStats {
+		e, err := letterExpectations(p)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+			exit(exitCode(err))
+		}
+		records := make([]any, len(e))
+		for i, v := range e {
+			records[i] = letterRecord{Letter: string(rune('A' + i)), Expected: v}
+		}
+		return formatAll(rw.f, records...)
+	}
 
-// main reads a digit string from a file and prints the number of decode ways.
-//
-// The file is read using memory-mapped I/O for efficient handling of large files.
-// The filename is provided as the first command-line argument.
-//
-// Usage:
-//   decode-ways <filename>
-//
-// Example:
-//   decode-ways test2.txt
-func main() {
-	// Check if filename argument is provided
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: decode-ways <filename>")
-		fmt.Fprintln(os.Stderr, "Example: decode-ways test2.txt")
-		os.Exit(1)
+	if *entropy {
+		points, total, err := decodingEntropy(p)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+			exit(exitCode(err))
+		}
+		records := make([]any, 0, len(points)+1)
+		for _, c := range points {
+			records = append(records, c)
+		}
+		records = append(records, choicePoint{Pos: -1, Reach: 1, Entropy: total})
+		return formatAll(rw.f, records...)
 	}
 
-	filename := os.Args[1]
+	if *lengths {
+		g, err := lengthDistribution(p)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+			exit(exitCode(err))
+		}
+		var records []any
+		for k, x := range g.c {
+			if x.Sign() != 0 {
+				records = append(records, lengthRecord{Length: g.lo + k, Count: x.String()})
+			}
+		}
+		return formatAll(rw.f, records...)
+	}
 
-	// Open file using memory-mapped I/O for efficient reading
-	r, err := mmap.Open(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening file '%s': %v\n", filename, err)
-		os.Exit(1)
+	if *match != "" {
+		g, err := newGlobMatcher(*match)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			exit(1)
+		}
+		if *list > 0 {
+			d, err := listMatching(p, g, *list)
+			if err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+				exit(exitCode(err))
+			}
+			records := make([]any, len(d))
+			for i, s := range d {
+				records[i] = decodingRecord{Decoding: s}
+			}
+			return formatAll(rw.f, records...)
+		}
+		x, err := countMatching(p, g)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+			exit(exitCode(err))
+		}
+		return formatAll(rw.f, matchRecord{Pattern: *match, Count: x.String()})
 	}
 
-	// Read entire file content into memory
-	p := make([]byte, r.Len())
-	if _, err = r.ReadAt(p, 0); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file '%s': %v\n", filename, err)
-		os.Exit(1)
+	if *best || *top > 0 {
+		m := englishModel
+		if *freqFile != "" {
+			if m, err = loadLetterModel(*freqFile); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error loading frequencies: %v\n", trError(err))))
+				exit(1)
+			}
+		}
+		if *best {
+			s, score, err := bestDecoding(p, m)
+			if err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+				exit(exitCode(err))
+			}
+			return formatAll(rw.f, scoreRecord{Decoding: s, Score: score, best: true})
+		}
+		d, err := topDecodings(p, m, *top)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+			exit(exitCode(err))
+		}
+		records := make([]any, len(d))
+		for i, x := range d {
+			records[i] = scoreRecord{Decoding: x.Text, Score: x.Score}
+		}
+		return formatAll(rw.f, records...)
 	}
 
 	// Calculate number of possible decodings
-	x, err := getPossibleCombinations(p)
+	if *checkpointFile != "" {
+		x, err := countCheckpointed(c, filename, p, checkpointConfig{
+			path:     *checkpointFile,
+			interval: *checkpointInterval,
+			resume:   *resume,
+		})
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+			rw.write(filename, countValue{}, err, rw.info(c, p), true, time.Since(start))
+			exit(exitCode(err))
+		}
+		return printResult(rw, c, filename, p, rw.value(x), time.Since(start))
+	}
+	r, err := rw.count(c, p)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error decoding: %v\n", err)
-		os.Exit(1)
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error decoding: %v\n", trError(err))))
+		rw.write(filename, countValue{}, err, rw.info(c, p), true, time.Since(start))
+		if !c.wide && !c.lenient && !hasWildcards(p) {
+			printRepairs(os.Stderr, p, c.s, err)
+		}
+		exit(exitCode(err))
+	}
+	if *crossCheck {
+		switch {
+		case c.wide || c.lenient || c.zeros == zerosSplit || hasWildcards(p):
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --cross-check only supports plain inputs with codes of up to two digits\n")))
+			exit(1)
+		case len(p) > crossCheckMax:
+			fmt.Fprintf(os.Stderr, "cross-check: skipped, the input is longer than %d characters\n", crossCheckMax)
+		default:
+			report, err := c.crossCheck(p, r.decimal())
+			if err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+				exit(1)
+			}
+			fmt.Fprintln(os.Stderr, report)
+		}
 	}
 
 	// Print result
-	fmt.Print(x)
+	return printResult(rw, c, filename, p, r, time.Since(start))
 }
@@ -0,0 +1,267 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// globMatcher is an automaton recognizing decodings that match a glob pattern.
+//
+// The pattern consists of letters A-Z, '?' matching any single letter and
+// '*' matching any (possibly empty) sequence of letters. It is compiled to an
+// NFA whose state j means "the first j pattern characters are matched"; sets
+// of NFA states are stored as bitmasks and serve as DFA states, with the
+// transitions computed lazily.
+type globMatcher struct {
+	pat   []byte
+	trans map[uint64]*[26]uint64 // Memoized DFA transitions
+}
+
+// newGlobMatcher compiles a glob pattern.
+//
+// Parameters:
+//   - pattern: The pattern, at most 63 characters long
+//
+// Returns:
+//   - *globMatcher: The compiled matcher
+//   - error: An error if the pattern is too long or contains an invalid character
+func newGlobMatcher(pattern string) (*globMatcher, error) {
+	if len(pattern) > 63 {
+		return nil, errors.New("pattern is longer than 63 characters")
+	}
+	pat := []byte(pattern)
+	for i, c := range pat {
+		if c >= 'a' && c <= 'z' {
+			pat[i] = c &^ 0x20 // Upper case
+		} else if (c < 'A' || c > 'Z') && c != '?' && c != '*' {
+			return nil, fmt.Errorf("invalid character %q in pattern at pos. %d", c, i)
+		}
+	}
+	return &globMatcher{pat: pat, trans: map[uint64]*[26]uint64{}}, nil
+}
+
+// closure adds to s the states reachable by letting a '*' match nothing.
+func (g *globMatcher) closure(s uint64) uint64 {
+	for j, c := range g.pat {
+		if c == '*' && s&(1<<j) != 0 {
+			s |= 1 << (j + 1)
+		}
+	}
+	return s
+}
+
+// start returns the initial DFA state.
+func (g *globMatcher) start() uint64 {
+	return g.closure(1)
+}
+
+// accepting reports whether the DFA state matches the whole pattern.
+func (g *globMatcher) accepting(s uint64) bool {
+	return s&(1<<len(g.pat)) != 0
+}
+
+// step returns the DFA state reached from s by reading letter c (0 = 'A').
+func (g *globMatcher) step(s uint64, c byte) uint64 {
+	t, ok := g.trans[s]
+	if !ok {
+		t = new([26]uint64)
+		for l := byte(0); l < 26; l++ {
+			var n uint64
+			for r := s; r != 0; r &= r - 1 {
+				j := bits.TrailingZeros64(r)
+				if j == len(g.pat) {
+					continue
+				}
+				switch g.pat[j] {
+				case '*':
+					n |= 1 << j
+				case '?', 'A' + l:
+					n |= 1 << (j + 1)
+				}
+			}
+			t[l] = g.closure(n)
+		}
+		g.trans[s] = t
+	}
+	return t[c]
+}
+
+// decodingSteps returns the letters (0 = 'A') of the one- and two-digit codes
+// ending at position i of p, or -1 where there is no such code.
+func decodingSteps(p []byte, i int) (one, two int) {
+	one, two = -1, -1
+	if p[i] != 0x30 {
+		one = int(p[i] - 0x31)
+	}
+	if i > 0 {
+		if v := int(p[i-1]-0x30)*10 + int(p[i]-0x30); v >= 10 && v <= 26 {
+			two = v - 1
+		}
+	}
+	return one, two
+}
+
+// matchRecord is the record of the count of --match.
+type matchRecord struct {
+	Pattern string `json:"pattern"`
+	Count   string `json:"count"` // Number of matching decodings in decimal
+}
+
+// plain implements plainRecord: the count alone, like the count mode.
+func (r matchRecord) plain() string {
+	return r.Count
+}
+
+// countMatching counts the decodings of a digit string that match the pattern.
+//
+// This is the product of the decoding DAG with the pattern DFA: for every
+// prefix the number of its decodings is tracked per DFA state, and a prefix
+// of length i is reached from i-1 by a one-digit letter and from i-2 by a
+// two-digit one.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - g: Compiled pattern
+//
+// Returns:
+//   - *big.Int: The number of matching decodings
+//   - error: An error if p contains a non-digit character
+//
+// Time Complexity: O(n * s) big.Int additions, s being the number of live DFA states
+func countMatching(p []byte, g *globMatcher) (*big.Int, error) {
+	prev2 := map[uint64]*big.Int{}
+	prev1 := map[uint64]*big.Int{g.start(): big.NewInt(1)}
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return nil, fmt.Errorf("encountered non-digit character at pos. %d", i)
+		}
+		cur := map[uint64]*big.Int{}
+		add := func(from map[uint64]*big.Int, c int) {
+			for s, x := range from {
+				t := g.step(s, byte(c))
+				if t == 0 {
+					continue // Dead state, can not match any more
+				}
+				if y, ok := cur[t]; ok {
+					y.Add(y, x)
+				} else {
+					cur[t] = new(big.Int).Set(x)
+				}
+			}
+		}
+		one, two := decodingSteps(p, i)
+		if one >= 0 {
+			add(prev1, one)
+		}
+		if two >= 0 {
+			add(prev2, two)
+		}
+		prev2, prev1 = prev1, cur
+	}
+
+	total := new(big.Int)
+	for s, x := range prev1 {
+		if g.accepting(s) {
+			total.Add(total, x)
+		}
+	}
+	return total, nil
+}
+
+// listMatching enumerates up to limit decodings of a digit string matching the pattern.
+//
+// The decodings are found by a depth-first walk of the decoding DAG. To make
+// large inputs feasible, a backward pass first computes for every position
+// the NFA states from which the rest of the input can still complete a match,
+// so the walk never enters a dead end.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - g: Compiled pattern
+//   - limit: Maximum number of decodings to return
+//
+// Returns:
+//   - []string: The matching decodings in lexicographic order of their codes
+//   - error: An error if p contains a non-digit character
+func listMatching(p []byte, g *globMatcher, limit int) ([]string, error) {
+	n := len(p)
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return nil, fmt.Errorf("encountered non-digit character at pos. %d", i)
+		}
+	}
+
+	// viable[i] holds the NFA states that can still reach a match from position i
+	viable := make([]uint64, n+1)
+	for j := 0; j <= len(g.pat); j++ {
+		if g.accepting(g.closure(1 << j)) {
+			viable[n] |= 1 << j
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		one, _ := decodingSteps(p, i)
+		two := -1
+		if i+1 < n {
+			_, two = decodingSteps(p, i+1)
+		}
+		for j := 0; j < len(g.pat); j++ {
+			s := uint64(1) << j
+			if (one >= 0 && g.step(s, byte(one))&viable[i+1] != 0) ||
+				(two >= 0 && g.step(s, byte(two))&viable[i+2] != 0) {
+				viable[i] |= s
+			}
+		}
+	}
+
+	// Depth-first walk with an explicit stack, as paths can be very long
+	type frame struct {
+		pos   int
+		state uint64
+		next  int // 0: try the one-digit code, 1: the two-digit one, 2: done
+	}
+	var res []string
+	letters := make([]byte, 0, n)
+	stack := []frame{{0, g.start(), 0}}
+	if g.start()&viable[0] == 0 {
+		return nil, nil
+	}
+	for len(stack) > 0 && len(res) < limit {
+		f := &stack[len(stack)-1]
+		if f.pos == n {
+			res = append(res, string(letters))
+		}
+		if f.pos == n || f.next == 2 {
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				letters = letters[:len(letters)-1]
+			}
+			continue
+		}
+		w := f.next + 1
+		f.next++
+		if f.pos+w > n {
+			continue
+		}
+		var c int
+		if w == 1 {
+			c, _ = decodingSteps(p, f.pos)
+		} else {
+			_, c = decodingSteps(p, f.pos+1)
+		}
+		if c < 0 {
+			continue
+		}
+		if t := g.step(f.state, byte(c)); t&viable[f.pos+w] != 0 {
+			letters = append(letters, byte('A'+c))
+			stack = append(stack, frame{f.pos + w, t, 0})
+		}
+	}
+	return res, nil
+}
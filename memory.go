@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime/debug"
+
+	"task1/decodeways"
+)
+
+// maxMemory is the memory budget in bytes, 0 if unlimited. It is set from the
+// --max-memory flag.
+var maxMemory int64
+
+// setMaxMemory sets the memory budget.
+//
+// Besides the estimates checked by checkMemory, the budget is passed to the
+// runtime as a soft memory limit, so the garbage collector works harder
+// instead of letting the heap grow past it.
+//
+// Parameters:
+//   - n: The budget in bytes, 0 for unlimited
+func setMaxMemory(n int64) {
+	maxMemory = n
+	if n > 0 {
+		debug.SetMemoryLimit(n)
+	}
+}
+
+// formatSize formats a byte count with a binary unit, e.g. "1.5 GiB".
+func formatSize(n int64) string {
+	const units = "KMGTPE"
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, i := float64(n)/1024, 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %ciB", v, units[i])
+}
+
+// checkMemory fails fast if an operation is estimated to exceed the budget.
+//
+// Parameters:
+//   - need: The estimated peak memory in bytes
+//
+// Returns:
+//   - error: An error if the estimate exceeds the budget
+func checkMemory(need int64) error {
+	if maxMemory > 0 && need > maxMemory {
+		return &limitError{fmt.Sprintf("estimated memory %s exceeds the limit of %s", formatSize(need), formatSize(maxMemory))}
+	}
+	return nil
+}
+
+// checkInputLen fails if an input is longer than the limit of --max-len.
+//
+// Parameters:
+//   - n: Length of the input in bytes
+//   - limit: The limit in bytes, 0 for none
+//
+// Returns:
+//   - error: An error if the input is longer than the limit
+func checkInputLen(n, limit int64) error {
+	if limit > 0 && n > limit {
+		return &limitError{fmt.Sprintf("input of %d bytes (%s) is longer than the --max-len limit of %s", n, formatSize(n), formatSize(limit))}
+	}
+	return nil
+}
+
+// memoryEstimate estimates the peak memory of multiplying the cluster factors
+// and printing the product, on top of the input of n bytes.
+//
+// The size of the product is known before multiplying: log2 F(k) is
+// k*log2(phi) - log2(sqrt(5)) up to a vanishing error, so the bits of all
+// factors are summed. The product tree holds up to about three times the
+// result (the operands of the last multiplication and the product, plus the
+// pooled temporaries), and the decimal conversion needs the digits, about
+// 0.3 bytes per bit, plus conversion temporaries of the size of the result.
+//
+// Parameters:
+//   - fc: The cluster factors
+//   - n: Size of the input in bytes
+//
+// Returns:
+//   - int64: The estimated peak memory in bytes
+func memoryEstimate(fc decodeways.Factors, n int) int64 {
+	bits := 0.0
+	for k, c := range fc {
+		bits += float64(c) * math.Max(float64(k)*math.Log2(math.Phi)-math.Log2(math.Sqrt(5)), 1)
+	}
+	bytes := bits / 8
+	return int64(n) + int64(4*bytes+bits*math.Log10(2))
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import "golang.org/x/text/language"
+
+// ukrainian is the built-in Ukrainian catalog. Messages without an entry are
+// printed in English.
+var ukrainian = map[string]string{
+	// Errors
+	"Error decoding: %v\n":                           "Помилка декодування: %v\n",
+	"Error: %v\n":                                    "Помилка: %v\n",
+	"Error %v\n":                                     "Помилка %v\n",
+	"Error: input: %v\n":                             "Помилка: вхідні дані: %v\n",
+	"%s at pos. %d":                                  "%s на поз. %d",
+	"encountered non-digit character":                "трапився нецифровий символ",
+	"string starts with non-digit character":         "рядок починається з нецифрового символу",
+	"string can not be decoded":                      "рядок неможливо декодувати",
+	"empty string":                                   "порожній рядок",
+	"encountered %c which can not be attached to %c": "трапилася %c, яку неможливо приєднати до %c",
+	"string starts with %c":                          "рядок починається з %c",
+	"Possible fixes:":                                "Можливі виправлення:",
+	"delete %q at pos. %d":                           "видалити %q на поз. %d",
+	"replace %q at pos. %d with %s":                  "замінити %q на поз. %d на %s",
+	"%q or %q":                                       "%q чи %q",
+	"any of %q":                                      "будь-який з %q",
+	"  ... and %d more\n":                            "  ... і ще %d\n",
+	"No single-character edit makes the input decodable.": "Жодна заміна чи видалення одного символу не робить вхідні дані декодованими.",
+
+	// Errors of the subcommands and their flags
+	"Error decoding %s: %v\n":                                        "Помилка декодування %s: %v\n",
+	"Error: unknown profile %q\n":                                    "Помилка: невідомий профіль %q\n",
+	"Error: --fail-over: %v\n":                                       "Помилка: --fail-over: %v\n",
+	"Error: throughput dropped by more than %s on %d of %d inputs\n": "Помилка: пропускна здатність впала більш ніж на %s на %d з %d вхідних даних\n",
+	"Error: --size: %v\n":                                            "Помилка: --size: %v\n",
+	"Error: --zero-density must be between 0 and 1\n":                "Помилка: --zero-density має бути між 0 і 1\n",
+	"Error: --count and --max-len must be positive\n":                "Помилка: --count і --max-len мають бути додатними\n",
+	"Error writing %s: %v\n":                                         "Помилка запису %s: %v\n",
+	"Error: --server: %v\n":                                          "Помилка: --server: %v\n",
+	"Error: compare needs exact counts, not --mod or --at-least\n":   "Помилка: compare потребує точних кількостей, без --mod чи --at-least\n",
+	"Error: unknown shell %q\n":                                      "Помилка: невідома оболонка %q\n",
+	"Error: invalid count %q\n":                                      "Помилка: недопустима кількість %q\n",
+	"Error constructing: %v\n":                                       "Помилка побудови: %v\n",
+	"Error: --fib-cache-size: %v\n":                                  "Помилка: --fib-cache-size: %v\n",
+	"Error: --result-cache-size: %v\n":                               "Помилка: --result-cache-size: %v\n",
+	"Error: encode does not support --codes, whose codes are not numbered letters\n": "Помилка: encode не підтримує --codes, чиї коди не є номерами літер\n",
+	"Error encoding: %v\n":                              "Помилка кодування: %v\n",
+	"Error: --qps and --concurrency must be positive\n": "Помилка: --qps і --concurrency мають бути додатними\n",
+	"Error: %s answered %s\n":                           "Помилка: %s відповів %s\n",
+	"Error: --target: %v\n":                             "Помилка: --target: %v\n",
+	"Error: --max-memory: %v\n":                         "Помилка: --max-memory: %v\n",
+	"Error: --resume needs --checkpoint\n":              "Помилка: --resume потребує --checkpoint\n",
+	"Error: --best, --top, --dag, --match, --letter-stats, --entropy and --lengths only support the alphabet 'A' = 1, ..., 'Z' = 26\n": "Помилка: --best, --top, --dag, --match, --letter-stats, --entropy і --lengths підтримують лише алфавіт 'A' = 1, ..., 'Z' = 26\n",
+	"Error: --at-least can not be combined with --format %s, which has no \"≥\"\n":                                                     "Помилка: --at-least неможливо поєднати з --format %s, у якому немає \"≥\"\n",
+	"Error: --null reads the inputs from stdin and only supports counting, without files and --sqlite\n":                               "Помилка: --null читає вхідні дані з stdin і підтримує лише підрахунок, без файлів і --sqlite\n",
+	"Error: --report: %v\n":                                                             "Помилка: --report: %v\n",
+	"Error: --check and --dry-run can not be combined\n":                                "Помилка: --check і --dry-run неможливо поєднати\n",
+	"Error: only counting supports several files, --sqlite and --report\n":              "Помилка: лише підрахунок підтримує кілька файлів, --sqlite і --report\n",
+	"Error: opening %s: %v\n":                                                           "Помилка: відкриття %s: %v\n",
+	"Error writing DAG: %v\n":                                                           "Помилка запису DAG: %v\n",
+	"Error: --explain only supports codes of up to two digits\n":                        "Помилка: --explain підтримує лише коди до двох цифр\n",
+	"Error: --cluster-stats only supports codes of up to two digits\n":                  "Помилка: --cluster-stats підтримує лише коди до двох цифр\n",
+	"Error loading frequencies: %v\n":                                                   "Помилка завантаження частот: %v\n",
+	"Error: --cross-check only supports plain inputs with codes of up to two digits\n":  "Помилка: --cross-check підтримує лише звичайні вхідні дані з кодами до двох цифр\n",
+	"Error reading stdin: %v\n":                                                         "Помилка читання stdin: %v\n",
+	"Error writing memory profile: %v\n":                                                "Помилка запису профілю пам'яті: %v\n",
+	"Error: --cache-size: %v\n":                                                         "Помилка: --cache-size: %v\n",
+	"Error: --max-input: %v\n":                                                          "Помилка: --max-input: %v\n",
+	"Error: --store-size: %v\n":                                                         "Помилка: --store-size: %v\n",
+	"Error: --compress: %v\n":                                                           "Помилка: --compress: %v\n",
+	"Error: loading API keys: %v\n":                                                     "Помилка: завантаження ключів API: %v\n",
+	"Error: --quota-bytes: %v\n":                                                        "Помилка: --quota-bytes: %v\n",
+	"Error: --quota-bytes and --quota-cpu need --api-keys, as quotas are per API key\n": "Помилка: --quota-bytes і --quota-cpu потребують --api-keys, бо квоти задаються для ключа API\n",
+	"Error: --quota-window must be positive\n":                                          "Помилка: --quota-window має бути додатним\n",
+	"Error: --compute-workers and --compute-queue must not be negative\n":               "Помилка: --compute-workers і --compute-queue не мають бути від'ємними\n",
+	"Error: --job-workers must be positive\n":                                           "Помилка: --job-workers має бути додатним\n",
+	"Error: --max-job-input: %v\n":                                                      "Помилка: --max-job-input: %v\n",
+	"Error: --jobs-dir: %v\n":                                                           "Помилка: --jobs-dir: %v\n",
+	"Error: --h2c is for plain text, with --tls-cert HTTP/2 is negotiated anyway\n":     "Помилка: --h2c для відкритого тексту, з --tls-cert HTTP/2 узгоджується й так\n",
+
+	// Errors of the reports of the count subcommand
+	"Error: --dag, --explain and --cluster-stats write text and do not support --format\n": "Помилка: --dag, --explain і --cluster-stats пишуть текст і не підтримують --format\n",
+
+	// Usage
+	"Usage:":    "Використання:",
+	"Example:":  "Приклад:",
+	"Commands:": "Команди:",
+	"Flags:":    "Прапорці:",
+	"count the decodings of files (the default)":                                        "порахувати декодування файлів (типово)",
+	"list the decodings of a file":                                                      "перелічити декодування файлу",
+	"check that letters are a decoding of a file":                                       "перевірити, що літери є декодуванням файлу",
+	"encode letters into digits":                                                        "закодувати літери цифрами",
+	"build an input with exactly n decodings":                                           "побудувати вхідні дані з рівно n декодуваннями",
+	"write a random input or test vectors":                                              "записати випадкові вхідні дані чи тестові вектори",
+	"benchmark synthetic inputs":                                                        "виміряти швидкодію на синтетичних вхідних даних",
+	"check the binary against known answers":                                            "перевірити програму на відомих відповідях",
+	"serve counts over HTTP, WebSocket and gRPC":                                        "обслуговувати підрахунки через HTTP, WebSocket і gRPC",
+	"serve counts over a Unix socket":                                                   "обслуговувати підрахунки через сокет Unix",
+	"count the files dropped into a directory":                                          "рахувати файли, покладені в каталог",
+	"print a shell completion script":                                                   "вивести скрипт автодоповнення для оболонки",
+	"use the mapping 'A' -> 0, ..., 'Z' -> 25 when counting":                            "рахувати з відображенням 'A' -> 0, ..., 'Z' -> 25",
+	"number of `letters` in the alphabet when counting":                                 "кількість літер (`letters`) в алфавіті",
+	"number of `workers` scanning a large input in parallel":                            "кількість потоків (`workers`), що паралельно сканують великі вхідні дані",
+	"print the count modulo `m` (0 < m < 2^63) using constant memory":                   "вивести кількість за модулем `m` (0 < m < 2^63) у сталій пам'яті",
+	"report every invalid position of an invalid input, not only the first":             "повідомити всі недопустимі позиції, а не лише першу",
+	"skip non-digit characters as boundaries between runs of digits, with a warning":    "пропускати нецифрові символи як межі між групами цифр, з попередженням",
+	"print every cluster with its Fibonacci factor and the running product":             "вивести кожен кластер з його множником Фібоначчі та проміжним добутком",
+	"report the time and throughput of every phase on stderr":                           "повідомити час і пропускну здатність кожної фази в stderr",
+	"do not show a progress bar while scanning large inputs":                            "не показувати індикатор поступу під час сканування великих вхідних даних",
+	"number of `files` counted concurrently when several are given":                     "кількість файлів (`files`), що рахуються одночасно",
+	"fail fast if the estimated memory use exceeds `size` (e.g. 2G)":                    "завершитися одразу, якщо оцінка пам'яті перевищує `size` (напр. 2G)",
+	"lowest `level` of the events logged on stderr: debug, info, warn or error":         "найнижчий рівень (`level`) подій журналу в stderr: debug, info, warn чи error",
+	"`format` of the log: text or json":                                                 "формат (`format`) журналу: text чи json",
+	"`language` of the messages, e.g. uk (default from $LC_ALL, $LC_MESSAGES or $LANG)": "мова (`language`) повідомлень, напр. uk (типово з $LC_ALL, $LC_MESSAGES чи $LANG)",
+}
+
+func init() {
+	addCatalog(language.Ukrainian, ukrainian)
+}
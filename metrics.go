@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"expvar"
+	"log/slog"
+	"net/http"
+)
+
+// The runtime metrics, published with expvar as the map "decode_ways" next
+// to the memstats and command line of the runtime. serve has them at
+// /debug/vars, daemon on the address of --metrics-addr; they are JSON any
+// monitoring agent can poll, without the Prometheus stack.
+var (
+	metricInputs    = new(expvar.Int) // Inputs counted, from the cache or not
+	metricBytes     = new(expvar.Int) // Bytes of the inputs
+	metricCacheHits = new(expvar.Int) // Inputs answered from a cache
+	metricErrors    = new(expvar.Int) // Inputs that could not be counted
+
+	metricComputeRunning  = new(expvar.Int) // Counts holding a compute worker
+	metricComputeWaiting  = new(expvar.Int) // Counts waiting for a compute worker
+	metricComputeRejected = new(expvar.Int) // Counts refused by a full compute queue
+)
+
+func init() {
+	m := expvar.NewMap("decode_ways")
+	m.Set("inputs", metricInputs)
+	m.Set("bytes", metricBytes)
+	m.Set("cache_hits", metricCacheHits)
+	m.Set("errors", metricErrors)
+	m.Set("compute_running", metricComputeRunning)
+	m.Set("compute_waiting", metricComputeWaiting)
+	m.Set("compute_rejected", metricComputeRejected)
+}
+
+// recordMetrics adds a counted input to the metrics.
+//
+// Parameters:
+//   - n: Length of the input in bytes
+//   - hit: Whether the count came from a cache
+//   - err: The error of the count, if any
+func recordMetrics(n int64, hit bool, err error) {
+	metricInputs.Add(1)
+	metricBytes.Add(n)
+	if hit {
+		metricCacheHits.Add(1)
+	}
+	if err != nil {
+		metricErrors.Add(1)
+	}
+}
+
+// serveMetrics serves the metrics at /debug/vars on an address, in the
+// background, for the processes without an HTTP server of their own.
+//
+// Parameters:
+//   - addr: The address, e.g. "localhost:6060"
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	go func() {
+		slog.Info("serving metrics", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("serving metrics failed", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"task1/decodeways"
+)
+
+// maxModulus bounds the modulus so that the sum of two residues fits in a uint64.
+const maxModulus = 1 << 63
+
+// countMod calculates the number of ways to decode a digit string modulo m.
+//
+// When only the residue is needed, the cluster algorithm and its big.Int
+// products are pointless: the classic two-variable DP
+//
+//	ways(i) = ways(i-1) * [p[i-1] is a code] + ways(i-2) * [p[i-2:i] is a code]
+//
+// is run entirely in machine words, reducing modulo m after every step. It
+// needs constant memory and neither touches the Fibonacci cache nor allocates.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to decode
+//   - s: The letter mapping scheme
+//   - m: The modulus, 0 < m < 2^63
+//
+// Returns:
+//   - uint64: The number of possible decodings modulo m
+//   - error: An error if the input is invalid
+//
+// Time Complexity: O(n), Space Complexity: O(1)
+func countMod(p []byte, s *decodeways.Scheme, m uint64) (uint64, error) {
+	if m == 0 || m >= maxModulus {
+		return 0, fmt.Errorf("modulus %d out of range", m)
+	}
+	if len(p) == 0 {
+		return 0, errors.New("empty string")
+	}
+
+	a := p[0] // Previous digit (for pair checking)
+
+	// Validate first character: must be a digit that is a code on its own
+	if a < 0x30 || a > 0x39 { // Not '0'-'9'
+		return 0, &decodeways.SyntaxError{Msg: "string starts with non-digit character", Pos: 0}
+	} else if !s.Single.Has(a - 0x30) {
+		return 0, &decodeways.SyntaxError{Msg: fmt.Sprintf("string starts with %c", a), Pos: 0}
+	}
+
+	prev2, prev1 := 1%m, 1%m // Ways for the prefixes ending two and one digits back
+	for i, b := range p[1:] {
+		// Validate that current character is a digit
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return 0, &decodeways.SyntaxError{Msg: "encountered non-digit character", Pos: i + 1}
+		}
+
+		single := s.Single.Has(b - 0x30)
+		pair := s.Pair[a-0x30].Has(b - 0x30)
+		if !single && !pair {
+			return 0, &decodeways.SyntaxError{Msg: fmt.Sprintf("encountered %c which can not be attached to %c", b, a), Pos: i + 1}
+		}
+
+		cur := uint64(0)
+		if single {
+			cur = prev1
+		}
+		if pair {
+			cur += prev2 // Both residues are below 2^63, so the sum does not overflow
+			if cur >= m {
+				cur -= m
+			}
+		}
+		prev2, prev1 = prev1, cur
+		a = b // Move to next digit
+	}
+	return prev1, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"task1/decodeways"
+)
+
+func TestCountMod(t *testing.T) {
+	tests := []struct {
+		in   string
+		m    uint64
+		want uint64
+	}{
+		{"12", 1_000_000_007, 2},
+		{"226", 1_000_000_007, 3},
+		{"226", 2, 1},
+		{"11106", 1_000_000_007, 2},
+		{"1111", 5, 0}, // F(5) = 5: a residue of 0 is still a count
+		{"1", 1, 0},
+		{"10", maxModulus - 1, 1},
+	}
+	for _, tt := range tests {
+		got, err := countMod([]byte(tt.in), &decodeways.StandardScheme, tt.m)
+		if err != nil || got != tt.want {
+			t.Errorf("countMod(%q, %d) = %d, %v, want %d", tt.in, tt.m, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "0", "06", "100", "1a", "30"} {
+		if got, err := countMod([]byte(in), &decodeways.StandardScheme, 1_000_000_007); err == nil {
+			t.Errorf("countMod(%q) = %d, want an error", in, got)
+		}
+	}
+	for _, m := range []uint64{0, maxModulus, maxModulus + 1} {
+		if _, err := countMod([]byte("12"), &decodeways.StandardScheme, m); err == nil {
+			t.Errorf("countMod accepts the modulus %d", m)
+		}
+	}
+}
+
+// TestCountModRandom checks the residues against the exact counts of the
+// cluster scan, for the standard and the zero-based scheme and for moduli
+// close to 2^63, where the sum of two residues needs the 64th bit.
+func TestCountModRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	zeroBased, err := newCodeRange(26, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemes := []decodeways.Scheme{decodeways.StandardScheme, zeroBased.scheme()}
+	moduli := []uint64{1_000_000_007, 998_244_353, maxModulus - 1, maxModulus - 25, 97}
+	for n := 0; n < 500; n++ {
+		sc := &schemes[n%len(schemes)]
+		m := moduli[n%len(moduli)]
+		var b strings.Builder
+		for b.Len() < 1+rng.Intn(400) {
+			b.WriteString([]string{"1", "2", "12", "26", "10", "3", "7"}[rng.Intn(7)])
+		}
+		p := []byte(b.String())
+		want, err := getPossibleCombinations(p, sc)
+		if err != nil {
+			continue // Invalid under the scheme
+		}
+		want.Mod(want, new(big.Int).SetUint64(m))
+		got, err := countMod(p, sc, m)
+		if err != nil || got != want.Uint64() {
+			t.Errorf("countMod(%q, %d) = %d, %v, want %v", p, m, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// runNull counts the NUL-separated digit strings of a stream (--null), like
+// xargs -0 takes its arguments, so producers of binary-safe records can pipe
+// them in without temporary files.
+//
+// The records are read and counted one at a time, and every one gets a
+// record named "stdin#<n>" (from 1), written like those of a batch: in the
+// plain format "stdin#<n>: <count>", in the order of the stream. A final NUL
+// is optional. As in a batch, a record that fails is reported on stderr and
+// the others are still counted.
+//
+// Parameters:
+//   - c: The counter
+//   - r: The stream, usually stdin
+//   - rw: The writer of the records
+//
+// Returns:
+//   - int: The process exit code: 0 if all records were counted, exitPartial
+//     if only some were, else that of the first record that failed (see
+//     exitCode)
+func runNull(c *counter, r io.Reader, rw *resultWriter) int {
+	br := bufio.NewReader(r)
+	code, n, failed := 0, 0, 0
+	for {
+		start := time.Now()
+		p, err := br.ReadBytes(0x00)
+		if err != nil && !errors.Is(err, io.EOF) {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error reading stdin: %v\n", trError(err))))
+			return exitIO
+		}
+		if len(p) == 0 {
+			break // End of the stream, or a final NUL before it
+		}
+		if p[len(p)-1] == 0x00 {
+			p = p[:len(p)-1]
+		}
+		n++
+		name := fmt.Sprintf("stdin#%d", n)
+		timings.addDigits(len(p))
+		var count countValue
+		cerr := checkMemory(int64(len(p)))
+		if cerr == nil {
+			count, cerr = rw.count(c, p)
+		}
+		if err := rw.write(name, count, cerr, rw.info(c, p), false, time.Since(start)); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+		if cerr != nil {
+			fmt.Fprint(os.Stderr, diagnostic(fmt.Sprintf("Error decoding %s: %v\n", name, trError(cerr))))
+			if code == 0 {
+				code = exitCode(cerr)
+			}
+			failed++
+		}
+		if err != nil {
+			break // io.EOF after a last record without a NUL
+		}
+	}
+	if failed > 0 && failed < n {
+		code = exitPartial
+	}
+	if err := rw.f.Flush(); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return exitIO
+	}
+	return code
+}
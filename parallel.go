@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"log/slog"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"task1/decodeways"
+)
+
+// minChunkSize is the smallest part of the input worth a worker of its own.
+const minChunkSize = 1 << 20
+
+// splitPoints divides a digit string into up to n chunks that can be scanned
+// independently.
+//
+// A boundary before position i is safe when p[i] is a code on its own and
+// does not form a code with p[i-1]: then neither a cluster nor a forced pair
+// (like "10") crosses it, and the number of decodings of the whole string is
+// the product of those of the two sides. Starting from evenly spaced
+// positions, the nearest safe boundary to the right is taken; in real inputs
+// one is found within a few digits.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - n: Requested number of chunks
+//   - s: The letter mapping scheme
+//
+// Returns:
+//   - []int: Chunk start positions, beginning with 0
+func splitPoints(p []byte, n int, s *decodeways.Scheme) []int {
+	starts := []int{0}
+	for k := 1; k < n; k++ {
+		i := len(p) * k / n
+		if i <= starts[len(starts)-1] {
+			i = starts[len(starts)-1] + 1
+		}
+		for ; i < len(p); i++ {
+			a, b := p[i-1], p[i]
+			if a >= 0x30 && a <= 0x39 && b >= 0x30 && b <= 0x39 &&
+				s.Single.Has(b-0x30) && !s.Pair[a-0x30].Has(b-0x30) {
+				break
+			}
+		}
+		if i >= len(p) {
+			break
+		}
+		starts = append(starts, i)
+	}
+	return starts
+}
+
+// countParallel calculates the number of ways to decode a digit string using
+// up to threads workers: the factors collected by scanParallel are multiplied
+// once at the end.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to decode
+//   - s: The letter mapping scheme
+//   - threads: Maximum number of concurrent workers
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if the input is invalid
+func countParallel(p []byte, s *decodeways.Scheme, threads int) (*big.Int, error) {
+	total, err := scanParallel(p, s, threads)
+	if err != nil {
+		return big.NewInt(0), err
+	}
+	if err := checkMemory(memoryEstimate(total, len(p))); err != nil {
+		return big.NewInt(0), err
+	}
+	start := time.Now()
+	x := total.Product()
+	timings.record("multiply", start)
+	slog.Info("multiplied", "factors", len(total), "bits", x.BitLen(), "duration", time.Since(start))
+	return x, nil
+}
+
+// scanParallel collects the cluster factors of a digit string using up to
+// threads workers.
+//
+// The input is split at safe boundaries (see splitPoints), the chunks are
+// scanned concurrently, each worker into its own factor counts, and the counts
+// are merged at the end. If several chunks are invalid, the error of the
+// first one is reported, as a sequential scan would.
+//
+// Inputs large enough for a progress bar are cut into chunks of
+// progressChunkSize even with a single worker, and every finished chunk
+// advances the bar and the scan status (see scanStatus).
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to decode
+//   - s: The letter mapping scheme
+//   - threads: Maximum number of concurrent workers
+//
+// Returns:
+//   - decodeways.Factors: The factors of the number of decodings
+//   - error: An error if the input is invalid
+func scanParallel(p []byte, s *decodeways.Scheme, threads int) (decodeways.Factors, error) {
+	if n := len(p) / minChunkSize; n < threads {
+		threads = n
+	}
+	chunks := threads
+	bar := newProgressBar(int64(len(p)))
+	if len(p) >= progressMinSize {
+		threads = max(threads, 1)
+		chunks = max(chunks, len(p)/progressChunkSize)
+	}
+	scans.begin(int64(len(p)))
+	start := time.Now()
+	if chunks <= 1 {
+		total := decodeways.Factors{}
+		if err := decodeways.ScanClusters(p, 0, s, total); err != nil {
+			return nil, err
+		}
+		scans.add(int64(len(p)), total)
+		timings.record("scan", start)
+		logScanned(len(p), total, start)
+		return total, nil
+	}
+
+	starts := splitPoints(p, chunks, s)
+	slog.Debug("scan started", "bytes", len(p), "chunks", len(starts), "threads", threads)
+	factors := make([]decodeways.Factors, threads)
+	errs := make([]error, len(starts))
+	var next atomic.Int64 // Next chunk to scan
+	var wg sync.WaitGroup
+	for w := range factors {
+		factors[w] = decodeways.Factors{}
+		wg.Add(1)
+		go func(fc decodeways.Factors) {
+			defer wg.Done()
+			for k := int(next.Add(1) - 1); k < len(starts); k = int(next.Add(1) - 1) {
+				start, end := starts[k], len(p)
+				if k+1 < len(starts) {
+					end = starts[k+1]
+				}
+				chunk := decodeways.Factors{}
+				errs[k] = decodeways.ScanClusters(p[start:end], start, s, chunk)
+				for f, c := range chunk {
+					fc[f] += c
+				}
+				bar.add(int64(end - start))
+				scans.add(int64(end-start), chunk)
+				slog.Debug("chunk scanned", "offset", start, "bytes", end-start)
+			}
+		}(factors[w])
+	}
+	wg.Wait()
+	bar.finish()
+	timings.record("scan", start)
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	total := decodeways.Factors{}
+	for _, fc := range factors {
+		for f, c := range fc {
+			total[f] += c
+		}
+	}
+	logScanned(len(p), total, start)
+	return total, nil
+}
+
+// logScanned logs the end of a scan.
+//
+// Parameters:
+//   - n: Size of the input in bytes
+//   - fc: The factors collected
+//   - start: The start time of the scan
+func logScanned(n int, fc decodeways.Factors, start time.Time) {
+	clusters, _ := factorBits(fc)
+	slog.Info("scan finished", "bytes", n, "clusters", clusters, "duration", time.Since(start))
+}
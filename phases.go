@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// phaseTimer accumulates the time spent in the phases of a count (reading,
+// scanning, multiplying, formatting), so that --stats can tell whether a run
+// is I/O-bound, scan-bound or bignum-bound. It is safe for concurrent use,
+// and all methods of a nil timer do nothing.
+type phaseTimer struct {
+	mu     sync.Mutex
+	names  []string // Phases in order of first appearance
+	total  map[string]time.Duration
+	digits int64 // Input digits counted
+}
+
+// timings is the phase timer of the run, nil unless --stats is given.
+var timings *phaseTimer
+
+// newPhaseTimer returns an empty phase timer.
+func newPhaseTimer() *phaseTimer {
+	return &phaseTimer{total: map[string]time.Duration{}}
+}
+
+// record adds the time since start to a phase.
+func (t *phaseTimer) record(name string, start time.Time) {
+	if t == nil {
+		return
+	}
+	d := time.Since(start)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.total[name]; !ok {
+		t.names = append(t.names, name)
+	}
+	t.total[name] += d
+}
+
+// addDigits adds the size of a counted input.
+func (t *phaseTimer) addDigits(n int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.digits += int64(n)
+	t.mu.Unlock()
+}
+
+// report writes the time of every phase with the throughput it corresponds
+// to: input digits per second and MB/s.
+//
+// Parameters:
+//   - w: The writer, usually stderr
+func (t *phaseTimer) report(w io.Writer) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "phase\ttime\tdigits/s\tMB/s\t")
+	for _, name := range t.names {
+		d := t.total[name]
+		rate := float64(t.digits) / d.Seconds()
+		fmt.Fprintf(tw, "%s\t%v\t%.4g\t%.1f\t\n", name, d.Round(time.Microsecond), rate, rate/(1<<20))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"task1/decodeways"
+)
+
+// planRecord is the plan of the count of an input (--dry-run).
+type planRecord struct {
+	File      string `json:"file"`
+	Algorithm string `json:"algorithm"`
+	Length    int    `json:"length"`
+	Segments  int    `json:"segments"`      // Independent segments, see countSegments
+	Clusters  uint64 `json:"clusters"`      // Clusters with a factor other than 1
+	Digits    int64  `json:"result_digits"` // Estimated decimal digits of the count
+	Memory    int64  `json:"memory"`        // Estimated peak memory in bytes
+	Error     string `json:"error,omitempty"`
+
+	single bool // Whether the input is the only one
+}
+
+// plain implements plainRecord: a line per figure, each prefixed with the
+// name of the file if there are several inputs.
+func (r planRecord) plain() string {
+	if r.Error != "" {
+		return ""
+	}
+	prefix := ""
+	if !r.single {
+		prefix = r.File + ": "
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%salgorithm: %s\n", prefix, r.Algorithm)
+	fmt.Fprintf(&b, "%slength: %d\n", prefix, r.Length)
+	fmt.Fprintf(&b, "%ssegments: %d\n", prefix, r.Segments)
+	fmt.Fprintf(&b, "%sclusters: %d\n", prefix, r.Clusters)
+	fmt.Fprintf(&b, "%sresult digits: ~%d\n", prefix, r.Digits)
+	fmt.Fprintf(&b, "%smemory: ~%s\n", prefix, formatSize(r.Memory))
+	return b.String()
+}
+
+// countSegments counts the independent segments of a digit string: the
+// parts between the safe boundaries of splitPoints, which no cluster or
+// forced pair crosses, so each could be counted on its own.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - s: The letter mapping scheme
+//
+// Returns:
+//   - int: The number of segments, 0 for an empty string
+func countSegments(p []byte, s *decodeways.Scheme) int {
+	if len(p) == 0 {
+		return 0
+	}
+	n := 1
+	for i := 1; i < len(p); i++ {
+		a, b := p[i-1], p[i]
+		if a >= 0x30 && a <= 0x39 && b >= 0x30 && b <= 0x39 &&
+			s.Single.Has(b-0x30) && !s.Pair[a-0x30].Has(b-0x30) {
+			n++
+		}
+	}
+	return n
+}
+
+// fibLog2 returns log2 F(k): from F(k) itself for small k, where the
+// closed form k*log2(phi) - log2(sqrt(5)) is off by the rounding of F(k).
+func fibLog2(k uint64) float64 {
+	if k > 64 {
+		return float64(k)*math.Log2(math.Phi) - math.Log2(math.Sqrt(5))
+	}
+	a, b := uint64(0), uint64(1)
+	for i := uint64(0); i < k; i++ {
+		a, b = b, a+b
+	}
+	return math.Log2(float64(a))
+}
+
+// plan scans an input and estimates the count without doing it (--dry-run):
+// the cluster scan only collects the factors, whose Fibonacci indices give
+// the size of the product (see memoryEstimate) before any big number is
+// computed.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//
+// Returns:
+//   - planRecord: The plan, without the file name
+//   - error: An error if the input is invalid, or is not counted by the
+//     cluster scan
+func (c *counter) plan(p []byte) (planRecord, error) {
+	r := planRecord{Algorithm: c.algorithm(p), Length: len(p)}
+	if r.Algorithm != "cluster-scan" {
+		return r, fmt.Errorf("--dry-run only plans the cluster scan, not the %s", r.Algorithm)
+	}
+	runs := [][2]int{{0, len(p)}}
+	if c.lenient {
+		runs = digitRuns(p)
+	}
+	if c.zeros == zerosSplit {
+		runs = splitStandalone(p, runs, c.s)
+	}
+	if len(runs) == 0 {
+		return r, errors.New("no digits in the input")
+	}
+
+	var fc decodeways.Factors
+	var err error
+	if len(runs) == 1 && runs[0] == [2]int{0, len(p)} {
+		fc, err = scanParallel(p, c.s, c.threads)
+	} else {
+		fc = decodeways.Factors{}
+		for _, run := range runs {
+			if err = decodeways.ScanClusters(p[run[0]:run[1]], run[0], c.s, fc); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil && c.zeros == zerosZero && digitError(err, p) {
+		fc, err = decodeways.Factors{}, nil // The count is 0
+	}
+	if err != nil {
+		return r, withLocation(err, p)
+	}
+
+	for _, run := range runs {
+		r.Segments += countSegments(p[run[0]:run[1]], c.s)
+	}
+	bits := 0.0
+	for k, n := range fc {
+		if k > 2 { // F(1) = F(2) = 1
+			r.Clusters += n
+			bits += float64(n) * fibLog2(k)
+		}
+	}
+	r.Digits = int64(bits*math.Log10(2)) + 1
+	r.Memory = memoryEstimate(fc, len(p))
+	return r, nil
+}
+
+// runPlan prints the plans of inputs without counting them (--dry-run).
+// Every input gets a record (see planRecord); the problem of an input that
+// can not be planned is also reported on stderr.
+//
+// Parameters:
+//   - c: The counter
+//   - files: Paths of the input files
+//   - f: The formatter of --format
+//
+// Returns:
+//   - int: The process exit code, that of the first file that failed (see
+//     exitCode)
+func runPlan(c *counter, files []string, f Formatter) int {
+	return runEach(files, c.maxLen, f, func(name string, p []byte, err error) (any, error) {
+		var r planRecord
+		if err == nil {
+			r, err = c.plan(p)
+		}
+		r.File, r.single = name, len(files) == 1
+		if err != nil {
+			r.Error, _, _ = strings.Cut(err.Error(), "\n") // One line, without the snippet
+		}
+		return r, err
+	})
+}
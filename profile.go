@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// stopProfiles finishes the profiles started by startProfiling. It must run
+// before the process exits, see exit.
+var stopProfiles = func() {}
+
+// startProfiling starts the profiles requested on the command line, so that
+// performance can be investigated without rebuilding the binary. The files
+// are read with "go tool pprof" and "go tool trace".
+//
+// Parameters:
+//   - cpuFile: File for the CPU profile, empty for none
+//   - memFile: File for the heap profile written at exit, empty for none
+//   - traceFile: File for the execution trace, empty for none
+//
+// Returns:
+//   - error: An error if a file can not be created or a profile started
+func startProfiling(cpuFile, memFile, traceFile string) error {
+	var stops []func()
+	stopProfiles = func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+		stops = nil
+	}
+
+	if cpuFile != "" {
+		f, err := os.Create(cpuFile)
+		if err != nil {
+			return fmt.Errorf("creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("starting CPU profile: %w", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			f.Close()
+		})
+	}
+
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			return fmt.Errorf("creating trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return fmt.Errorf("starting trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+		})
+	}
+
+	if memFile != "" {
+		f, err := os.Create(memFile)
+		if err != nil {
+			return fmt.Errorf("creating memory profile: %w", err)
+		}
+		stops = append(stops, func() {
+			runtime.GC() // Get up-to-date statistics
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error writing memory profile: %v\n", trError(err))))
+			}
+			f.Close()
+		})
+	}
+	return nil
+}
+
+// exit finishes the profiles and terminates the process with the given code.
+// os.Exit does not run deferred calls, so main exits through it instead.
+func exit(code int) {
+	stopProfiles()
+	os.Exit(code)
+}
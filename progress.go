@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// progressMinSize is the smallest input that gets a progress bar.
+const progressMinSize = 256 << 20
+
+// progressChunkSize is the granularity of the progress bar: the scan reports
+// progress after every chunk of about this size.
+const progressChunkSize = 16 << 20
+
+// showProgress enables the progress bar. It is cleared by --no-progress.
+var showProgress = true
+
+// progressBar draws the progress of a long scan on stderr: a bar, the
+// percentage, the bytes scanned and the estimated time left.
+type progressBar struct {
+	total int64
+	done  atomic.Int64
+	start time.Time
+	stop  chan struct{}
+	drawn chan struct{}
+}
+
+// newProgressBar starts a progress bar for a scan of total bytes.
+//
+// No bar is shown for small inputs, when disabled, or when stderr is not a
+// terminal (where the redrawn line would only clutter logs); nil is returned
+// then, and the methods of a nil bar do nothing.
+//
+// Parameters:
+//   - total: Size of the input in bytes
+//
+// Returns:
+//   - *progressBar: The running bar, or nil
+func newProgressBar(total int64) *progressBar {
+	if !showProgress || total < progressMinSize {
+		return nil
+	}
+	if fi, err := os.Stderr.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	b := &progressBar{total: total, start: time.Now(), stop: make(chan struct{}), drawn: make(chan struct{})}
+	go func() {
+		defer close(b.drawn)
+		t := time.NewTicker(200 * time.Millisecond)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				b.draw()
+			case <-b.stop:
+				b.draw()
+				fmt.Fprintln(os.Stderr)
+				return
+			}
+		}
+	}()
+	return b
+}
+
+// add records n more bytes scanned. It is safe for concurrent use.
+func (b *progressBar) add(n int64) {
+	if b != nil {
+		b.done.Add(n)
+	}
+}
+
+// finish draws the final state and ends the line.
+func (b *progressBar) finish() {
+	if b != nil {
+		close(b.stop)
+		<-b.drawn
+	}
+}
+
+// draw redraws the bar in place.
+func (b *progressBar) draw() {
+	const width = 30
+	done := b.done.Load()
+	frac := float64(done) / float64(b.total)
+	filled := int(frac * width)
+	eta := "--:--"
+	if elapsed := time.Since(b.start); done > 0 {
+		left := time.Duration(float64(elapsed) * (1/frac - 1)).Round(time.Second)
+		eta = fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+	}
+	fmt.Fprintf(os.Stderr, "\r[%s%s] %3.0f%%  %s / %s  ETA %s ",
+		strings.Repeat("#", filled), strings.Repeat(".", width-filled),
+		100*frac, formatSize(done), formatSize(b.total), eta)
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// version is the version of the tool. Release builds set it with
+// -ldflags "-X main.version=v1.2.3"; otherwise it is taken from the build
+// info, see toolVersion.
+var version string
+
+// toolVersion returns the version of the tool: the one set at link time, the
+// module version, or the VCS revision of the build ("+dirty" if the tree had
+// local changes), "devel" if none is known.
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	rev, dirty := "", false
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	switch {
+	case rev == "":
+		return "devel"
+	case dirty:
+		return "devel-" + rev[:min(len(rev), 12)] + "+dirty"
+	}
+	return "devel-" + rev[:min(len(rev), 12)]
+}
+
+// inputInfo describes an input for the provenance of its result.
+type inputInfo struct {
+	sum       []byte // SHA-256 of the input
+	length    int    // Length of the input in bytes
+	algorithm string // See counter.algorithm
+	dup       string // File of the batch with the same content, whose count is reused (--dedup)
+}
+
+// newInputInfo hashes an input and names the algorithm counting it.
+func newInputInfo(c *counter, p []byte) inputInfo {
+	h := sha256.Sum256(p)
+	return inputInfo{sum: h[:], length: len(p), algorithm: c.algorithm(p)}
+}
+
+// resultRecord is the record of a count: the count, or the error, with its
+// provenance in formats other than plain, so archived results can be
+// reproduced and audited.
+type resultRecord struct {
+	File      string   `json:"file"`
+	Count     string   `json:"count,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	SHA256    string   `json:"sha256,omitempty"`
+	Length    int      `json:"length"`
+	Version   string   `json:"version"`
+	Algorithm string   `json:"algorithm,omitempty"`
+	Flags     []string `json:"flags"`
+	DupOf     string   `json:"duplicate_of,omitempty"`
+
+	value  *big.Int // The count as a number, for the binary formats
+	single bool     // Whether the count is of a single input, printed alone
+}
+
+// plain implements plainRecord: the count alone for a single input,
+// "<filename>: <count>" in a batch.
+func (r resultRecord) plain() string {
+	switch {
+	case r.Error != "":
+		return ""
+	case r.single:
+		return r.Count
+	}
+	return r.File + ": " + r.Count + "\n"
+}
+
+// countValue is the count of a record: in decimal, or as a number for the
+// binary formats of --format, which are spared the conversion to decimal.
+type countValue struct {
+	s string   // The count in decimal, "" if not converted
+	x *big.Int // The count, nil if only known in decimal
+}
+
+// decimal returns the count in decimal, converting it if needed.
+func (v countValue) decimal() string {
+	if v.s == "" && v.x != nil {
+		return v.x.String()
+	}
+	return v.s
+}
+
+// number returns the count as a number, nil for none.
+func (v countValue) number() *big.Int {
+	if v.x == nil && v.s != "" {
+		x, _ := new(big.Int).SetString(v.s, 10)
+		return x
+	}
+	return v.x
+}
+
+// resultWriter writes the records of counts with the formatter of --format.
+type resultWriter struct {
+	f          Formatter
+	binary     bool         // Whether the format takes counts as numbers, see binaryFormats
+	flags      []string     // The flags given on the command line
+	provenance bool         // Whether the inputs are hashed, see info
+	log        *resultLog   // The log of --log-results, nil for none
+	report     *batchReport // The report of --report, nil for none
+}
+
+// newResultWriter returns the writer of the records of counts.
+//
+// Parameters:
+//   - w: The writer, usually stdout
+//   - format: The --format value
+//   - fs: The parsed flag set, whose flags go into the provenance
+//   - logPath: The CSV file of --log-results, empty for none
+//
+// Returns:
+//   - *resultWriter: The writer
+//   - error: An error if the format is unknown or the log can not be opened
+func newResultWriter(w io.Writer, format string, fs *flag.FlagSet, logPath string) (*resultWriter, error) {
+	f, err := newFormatter(w, format)
+	if err != nil {
+		return nil, err
+	}
+	rw := &resultWriter{f: f, binary: binaryFormats[format], flags: []string{}, provenance: format != "plain" || logPath != ""}
+	if logPath != "" {
+		if rw.log, err = openResultLog(logPath); err != nil {
+			return nil, fmt.Errorf("--log-results: %w", err)
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		rw.flags = append(rw.flags, "--"+f.Name+"="+f.Value.String())
+	})
+	return rw, nil
+}
+
+// count counts an input for its record: in decimal, or as a number without
+// the result cache for the binary formats (see counter.countInt).
+//
+// Parameters:
+//   - c: The counter
+//   - p: The input
+//
+// Returns:
+//   - countValue: The count
+//   - error: An error if the input is invalid
+func (rw *resultWriter) count(c *counter, p []byte) (countValue, error) {
+	if !rw.binary {
+		s, err := c.countInput(p)
+		return countValue{s: s}, err
+	}
+	x, err := c.countInt(p)
+	return countValue{x: x}, err
+}
+
+// value returns the count of a record from a number.
+func (rw *resultWriter) value(x *big.Int) countValue {
+	if rw.binary {
+		return countValue{x: x}
+	}
+	return countValue{s: x.String()}
+}
+
+// info describes an input for its record, unless neither the format, if it
+// is plain, nor a result log or report needs the provenance: then hashing the
+// input is skipped.
+func (rw *resultWriter) info(c *counter, p []byte) inputInfo {
+	if !rw.provenance && rw.report == nil {
+		return inputInfo{length: len(p)}
+	}
+	return newInputInfo(c, p)
+}
+
+// write writes the record of an input, and adds it to the result log and the
+// batch report.
+//
+// Parameters:
+//   - file: Path of the input
+//   - count: The count, if there is no error
+//   - err: The error of the count, if any
+//   - in: The input, its zero value if it could not be read
+//   - single: Whether the input is the only one
+//   - elapsed: The time taken by reading and counting the input
+//
+// Returns:
+//   - error: An error if the record can not be written
+func (rw *resultWriter) write(file string, count countValue, err error, in inputInfo, single bool, elapsed time.Duration) error {
+	r := resultRecord{File: file, Count: count.s, Length: in.length, Version: toolVersion(), Algorithm: in.algorithm, Flags: rw.flags, DupOf: in.dup, single: single}
+	if in.sum != nil {
+		r.SHA256 = hex.EncodeToString(in.sum)
+	}
+	if err != nil {
+		r.Error, _, _ = strings.Cut(err.Error(), "\n") // One line, without the snippet
+	}
+	if rw.binary {
+		r.value = count.number()
+	}
+	if rw.report != nil {
+		rw.report.add(file, count.decimal(), err, in, elapsed)
+	}
+	if rw.log != nil {
+		if err := rw.log.append(file, in.sum, count.decimal(), err, elapsed); err != nil {
+			return fmt.Errorf("--log-results: %w", err)
+		}
+	}
+	return rw.f.Format(r)
+}
+
+// printResult prints the count of a single input.
+//
+// Parameters:
+//   - rw: The writer of the records
+//   - c: The counter
+//   - file: Path of the input
+//   - p: The input
+//   - count: The count
+//   - elapsed: The time taken by reading and counting the input
+//
+// Returns:
+//   - int: The process exit code
+func printResult(rw *resultWriter, c *counter, file string, p []byte, count countValue, elapsed time.Duration) int {
+	if err := rw.write(file, count, nil, rw.info(c, p), true, elapsed); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return exitIO
+	}
+	return formatAll(rw.f)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// quota is how much an API key may count per quota window. A zero limit is
+// no limit.
+type quota struct {
+	bytes int64         // Bytes of input
+	cpu   time.Duration // Time spent counting
+}
+
+// parseQuota parses the quota of a key in the key file, given as fields
+// "bytes=<size>" and "cpu=<duration>".
+//
+// Parameters:
+//   - fields: The fields after the key
+//
+// Returns:
+//   - *quota: The quota, with no limit for the fields not given
+//   - error: An error if a field is invalid
+func parseQuota(fields []string) (*quota, error) {
+	q := &quota{}
+	for _, f := range fields {
+		name, value, _ := strings.Cut(f, "=")
+		var err error
+		switch name {
+		case "bytes":
+			q.bytes, err = parseSize(value)
+		case "cpu":
+			q.cpu, err = time.ParseDuration(value)
+		default:
+			err = fmt.Errorf("unknown quota %q (bytes or cpu)", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("quota %s: %w", f, err)
+		}
+	}
+	return q, nil
+}
+
+// keyUsage is what an API key counted in its current quota window.
+type keyUsage struct {
+	bytes int64
+	cpu   time.Duration
+	since time.Time // Start of the window
+}
+
+// quotaTracker tracks the usage of every API key and enforces the quotas.
+// Usage is counted over fixed windows, starting with the first request of a
+// key after the previous window ended. It is safe for concurrent use, and a
+// nil tracker tracks nothing.
+type quotaTracker struct {
+	keys   apiKeys       // The keys with their own quotas
+	def    quota         // Quota of the keys without their own
+	window time.Duration // Length of a quota window
+
+	mu    sync.Mutex
+	usage map[[sha256.Size]byte]*keyUsage
+}
+
+// newQuotaTracker returns the tracker of the API keys of a server, or nil if
+// authentication is disabled, as clients are only told apart by their keys.
+func newQuotaTracker(keys apiKeys, def quota, window time.Duration) *quotaTracker {
+	if keys == nil {
+		return nil
+	}
+	return &quotaTracker{keys: keys, def: def, window: window, usage: map[[sha256.Size]byte]*keyUsage{}}
+}
+
+// quotaOf returns the quota of a tenant.
+func (t *quotaTracker) quotaOf(tenant [sha256.Size]byte) quota {
+	if q := t.keys[tenant]; q != nil {
+		return *q
+	}
+	return t.def
+}
+
+// current returns the usage of a tenant in the current window, starting a
+// new window if the last one ended. The lock must be held.
+func (t *quotaTracker) current(tenant [sha256.Size]byte, now time.Time) *keyUsage {
+	u, ok := t.usage[tenant]
+	if !ok || now.Sub(u.since) >= t.window {
+		u = &keyUsage{since: now}
+		t.usage[tenant] = u
+	}
+	return u
+}
+
+// quotaError is the error of a request over the quota of its key.
+type quotaError struct {
+	msg   string
+	reset time.Duration // Time until the window ends
+}
+
+// Error implements the error interface.
+func (e *quotaError) Error() string {
+	return e.msg
+}
+
+// check tells whether a tenant may make another request.
+//
+// Returns:
+//   - error: A quotaError if the quota of the tenant is used up
+func (t *quotaTracker) check(tenant [sha256.Size]byte) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	u, q := t.current(tenant, now), t.quotaOf(tenant)
+	reset := u.since.Add(t.window).Sub(now)
+	switch {
+	case q.bytes > 0 && u.bytes >= q.bytes:
+		return &quotaError{fmt.Sprintf("quota exceeded: %s of input counted, %s allowed per %v; resets in %v", formatSize(u.bytes), formatSize(q.bytes), t.window, reset.Round(time.Second)), reset}
+	case q.cpu > 0 && u.cpu >= q.cpu:
+		return &quotaError{fmt.Sprintf("quota exceeded: %v spent counting, %v allowed per %v; resets in %v", u.cpu.Round(time.Millisecond), q.cpu, t.window, reset.Round(time.Second)), reset}
+	}
+	return nil
+}
+
+// charge adds a count to the usage of a tenant. A count may take the usage
+// over the quota: only the requests after it are refused.
+func (t *quotaTracker) charge(tenant [sha256.Size]byte, bytes int64, cpu time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := t.current(tenant, time.Now())
+	u.bytes += bytes
+	u.cpu += cpu
+}
+
+// usageReport is the body of GET /v1/usage. Limits of 0 are no limit.
+type usageReport struct {
+	WindowStart     time.Time `json:"window_start"`
+	WindowEnd       time.Time `json:"window_end"`
+	Bytes           int64     `json:"bytes"`
+	CPUSeconds      float64   `json:"cpu_seconds"`
+	QuotaBytes      int64     `json:"quota_bytes"`
+	QuotaCPUSeconds float64   `json:"quota_cpu_seconds"`
+}
+
+// report returns the usage and quota of a tenant.
+func (t *quotaTracker) report(tenant [sha256.Size]byte) usageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, q := t.current(tenant, time.Now()), t.quotaOf(tenant)
+	return usageReport{
+		WindowStart:     u.since.UTC(),
+		WindowEnd:       u.since.Add(t.window).UTC(),
+		Bytes:           u.bytes,
+		CPUSeconds:      u.cpu.Seconds(),
+		QuotaBytes:      q.bytes,
+		QuotaCPUSeconds: q.cpu.Seconds(),
+	}
+}
+
+// tenantKey is the context key of the tenant of a request.
+type tenantKey struct{}
+
+// withTenant returns a context carrying the tenant of an API key.
+func withTenant(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, sha256.Sum256([]byte(key)))
+}
+
+// tenantOf returns the tenant of a request, by the SHA-256 of its API key.
+func tenantOf(ctx context.Context) ([sha256.Size]byte, bool) {
+	t, ok := ctx.Value(tenantKey{}).([sha256.Size]byte)
+	return t, ok
+}
+
+// chargeCount charges a count to the tenant of a request, if it has one.
+func (sv *server) chargeCount(ctx context.Context, bytes int64, cpu time.Duration) {
+	if tenant, ok := tenantOf(ctx); ok {
+		sv.quotas.charge(tenant, bytes, cpu)
+	}
+}
+
+// meter refuses the POST requests and WebSocket upgrades of keys over their
+// quota with 429, and tags all requests with their tenant for charging their
+// counts (see chargeCount). Polls and usage reports are never refused.
+func (sv *server) meter(h http.Handler) http.Handler {
+	if sv.quotas == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := bearerKey(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+		ctx := withTenant(r.Context(), key)
+		if r.Method == http.MethodPost || websocket.IsWebSocketUpgrade(r) {
+			tenant, _ := tenantOf(ctx)
+			if qe, ok := sv.quotas.check(tenant).(*quotaError); ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(qe.reset.Seconds()))))
+				writeError(w, http.StatusTooManyRequests, qe.msg)
+				return
+			}
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// meteredStream is a gRPC server stream whose context carries its tenant.
+type meteredStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s *meteredStream) Context() context.Context {
+	return s.ctx
+}
+
+// meterStream refuses gRPC calls of keys over their quota with
+// RESOURCE_EXHAUSTED, and tags the others with their tenant.
+func (sv *server) meterStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+	if sv.quotas == nil {
+		return h(srv, ss)
+	}
+	ctx := withTenant(ss.Context(), grpcKey(ss.Context()))
+	tenant, _ := tenantOf(ctx)
+	if err := sv.quotas.check(tenant); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return h(srv, &meteredStream{ServerStream: ss, ctx: ctx})
+}
+
+// handleUsage implements GET /v1/usage: the usage and quota of the API key
+// of the request in the current window (see usageReport).
+func (sv *server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(r.Context())
+	if !ok {
+		writeError(w, http.StatusNotFound, "usage is only tracked with --api-keys")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sv.quotas.report(tenant))
+}
+
+// tenantHex returns the tenant of a request in hex, "" for none, to record it
+// with a job.
+func tenantHex(ctx context.Context) string {
+	if tenant, ok := tenantOf(ctx); ok {
+		return hex.EncodeToString(tenant[:])
+	}
+	return ""
+}
+
+// withTenantHex returns a context carrying a tenant recorded by tenantHex.
+func withTenantHex(ctx context.Context, s string) context.Context {
+	var tenant [sha256.Size]byte
+	if b, err := hex.DecodeString(s); err == nil && len(b) == len(tenant) {
+		copy(tenant[:], b)
+		return context.WithValue(ctx, tenantKey{}, tenant)
+	}
+	return ctx
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// tokenBucket is the rate limit state of one client.
+type tokenBucket struct {
+	tokens float64   // Requests the client may make right away
+	last   time.Time // Time tokens was last brought up to date
+}
+
+// rateLimiter limits the requests of every client with a token bucket: a
+// client may make burst requests at once, and then qps requests per second.
+// It is safe for concurrent use, and a nil limiter allows everything.
+type rateLimiter struct {
+	qps, burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	pruned  time.Time // Time full buckets were last dropped
+}
+
+// newRateLimiter returns a limiter of qps requests per second with bursts of
+// up to burst requests, or nil if qps is not positive.
+func newRateLimiter(qps float64, burst int) *rateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &rateLimiter{qps: qps, burst: float64(max(burst, 1)), buckets: map[string]*tokenBucket{}, pruned: time.Now()}
+}
+
+// allow takes a token from the bucket of a client.
+//
+// Parameters:
+//   - client: The client, an API key or IP address
+//
+// Returns:
+//   - bool: Whether the request may go ahead
+//   - time.Duration: If not, how long until it may
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+
+	// A bucket that has refilled is the same as none, so idle clients are
+	// dropped now and then to bound the memory spent on them
+	full := time.Duration(l.burst / l.qps * float64(time.Second))
+	if now.Sub(l.pruned) > full {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > full {
+				delete(l.buckets, k)
+			}
+		}
+		l.pruned = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.qps)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.qps * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// clientOf identifies the client of a request by its API key, or by its IP
+// address if it has none. Keys are only trusted if they are checked, as
+// clients could otherwise dodge the limit by making up a new key every time.
+func (sv *server) clientOf(key, addr string) string {
+	if key != "" && sv.keys != nil {
+		return "key " + key
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// limit rejects HTTP requests of clients over their rate with 429.
+func (sv *server) limit(h http.Handler) http.Handler {
+	if sv.limiter == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := bearerKey(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+		if ok, wait := sv.limiter.allow(sv.clientOf(key, r.RemoteAddr)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// limitStream rejects gRPC calls of clients over their rate with
+// RESOURCE_EXHAUSTED.
+func (sv *server) limitStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+	addr := ""
+	if p, ok := peer.FromContext(ss.Context()); ok {
+		addr = p.Addr.String()
+	}
+	if ok, _ := sv.limiter.allow(sv.clientOf(grpcKey(ss.Context()), addr)); !ok {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return h(srv, ss)
+}
+
+// grpcKey extracts the API key of a gRPC call from its metadata.
+func grpcKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(name string) string {
+		if v := md.Get(name); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	return bearerKey(first("authorization"), first("x-api-key"))
+}
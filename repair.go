@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"task1/decodeways"
+)
+
+// maxRepairs is the number of lines of repairs printed for an invalid input.
+const maxRepairs = 10
+
+// repair is a single-character edit of an input.
+type repair struct {
+	pos int
+	del bool // Whether the character is deleted rather than replaced
+	to  byte // Replacement character
+}
+
+// suggestRepairs finds the single-character edits (substitutions of a
+// digit, deletions) that make an invalid input decodable.
+//
+// The first invalid character at e can only be decoded with a code that
+// changes around it, so only edits near it can repair the input. Whether
+// the edited input is decodable is decided by a DP over reachable
+// positions, like the classic DP but with booleans: prefixes are followed
+// forward up to the edited window and suffixes backward down to it, once
+// each, and every candidate edit only runs the DP over the few characters of
+// the window in between.
+//
+// Parameters:
+//   - p: The input
+//   - s: The letter mapping scheme
+//   - e: Position of the first invalid character
+//
+// Returns:
+//   - []repair: The repairs, by position; none if it takes more than one edit
+func suggestRepairs(p []byte, s *decodeways.Scheme, e int) []repair {
+	single := func(c byte) bool { return c >= 0x30 && c <= 0x39 && s.Single.Has(c-0x30) }
+	pair := func(a int, c byte) bool { // a < 0 for no previous character
+		return a >= 0x30 && a <= 0x39 && c >= 0x30 && c <= 0x39 && s.Pair[a-0x30].Has(c-0x30)
+	}
+	n := len(p)
+	lo, hi := max(e-2, 0), min(e+2, n) // The edited window p[lo:hi]
+
+	// f0, f1: whether p[:lo-1] and p[:lo] are decodable
+	f0, f1 := false, true
+	for k := 0; k < lo; k++ {
+		a := -1
+		if k > 0 {
+			a = int(p[k-1])
+		}
+		f0, f1 = f1, single(p[k]) && f1 || pair(a, p[k]) && f0
+	}
+	// b0, b1: whether p[hi:] and p[hi+1:] are decodable
+	b0, b1 := true, false
+	for k := n - 1; k >= hi; k-- {
+		b0, b1 = single(p[k]) && b0 || k+1 < n && pair(int(p[k]), p[k+1]) && b1, b0
+	}
+
+	decodable := func(w []byte) bool {
+		g0, g1 := f0, f1
+		a := -1
+		if lo > 0 {
+			a = int(p[lo-1])
+		}
+		for _, c := range w {
+			g0, g1 = g1, single(c) && g1 || pair(a, c) && g0
+			a = int(c)
+		}
+		return g1 && b0 || hi < n && pair(a, p[hi]) && g0 && b1
+	}
+
+	var repairs []repair
+	w := make([]byte, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		if n > 1 {
+			w = append(append(w[:0], p[lo:i]...), p[i+1:hi]...)
+			if decodable(w) {
+				repairs = append(repairs, repair{pos: i, del: true})
+			}
+		}
+		w = append(w[:0], p[lo:hi]...)
+		for d := byte(0x30); d <= 0x39; d++ {
+			if d == p[i] {
+				continue
+			}
+			w[i-lo] = d
+			if decodable(w) {
+				repairs = append(repairs, repair{pos: i, to: d})
+			}
+		}
+	}
+	return repairs
+}
+
+// printRepairs prints the repairs of an input that failed to decode, if the
+// error points at an invalid character. Replacements of the same character
+// are listed together, e.g. "replace '3' at pos. 0 with '1' or '2'".
+//
+// Parameters:
+//   - w: The writer, usually stderr
+//   - p: The input
+//   - s: The letter mapping scheme
+//   - err: The error of the count
+func printRepairs(w io.Writer, p []byte, s *decodeways.Scheme, err error) {
+	var se *decodeways.SyntaxError
+	if !errors.As(err, &se) || se.Pos >= len(p) {
+		return
+	}
+	repairs := suggestRepairs(p, s, se.Pos)
+	if len(repairs) == 0 {
+		fmt.Fprintln(w, tr("No single-character edit makes the input decodable."))
+		return
+	}
+	var lines []string
+	for i := 0; i < len(repairs); i++ {
+		r := repairs[i]
+		if r.del {
+			lines = append(lines, tr("delete %q at pos. %d", p[r.pos], r.pos))
+			continue
+		}
+		to := []byte{r.to}
+		for i+1 < len(repairs) && repairs[i+1].pos == r.pos && !repairs[i+1].del {
+			i++
+			to = append(to, repairs[i].to)
+		}
+		var with string
+		switch len(to) {
+		case 1:
+			with = fmt.Sprintf("%q", to[0])
+		case 2:
+			with = tr("%q or %q", to[0], to[1])
+		default:
+			with = tr("any of %q", to)
+		}
+		lines = append(lines, tr("replace %q at pos. %d with %s", p[r.pos], r.pos, with))
+	}
+	fmt.Fprintln(w, tr("Possible fixes:"))
+	for i, line := range lines {
+		if i == maxRepairs {
+			fmt.Fprint(w, tr("  ... and %d more\n", len(lines)-maxRepairs))
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reportFile is the entry of a file in a batch report.
+type reportFile struct {
+	File    string  `json:"file"`
+	Count   string  `json:"count,omitempty"`
+	Error   string  `json:"error,omitempty"`
+	SHA256  string  `json:"sha256,omitempty"`
+	Length  int     `json:"length"`
+	Seconds float64 `json:"seconds"`
+	DupOf   string  `json:"duplicate_of,omitempty"`
+}
+
+// batchReport is the report of --report: the results of the files of a
+// batch and their aggregate, so an orchestrator gets a single artifact per
+// batch.
+type batchReport struct {
+	Version   string       `json:"version"`
+	Flags     []string     `json:"flags"`
+	Started   time.Time    `json:"started"`
+	Seconds   float64      `json:"seconds"`            // Wall time of the batch
+	Files     int          `json:"files"`              // Number of files
+	Failures  int          `json:"failures"`           // Number of files that failed
+	Dups      int          `json:"deduplicated"`       // Number of files whose count was reused
+	Digits    int64        `json:"total_digits"`       // Length of the inputs read
+	FileTime  float64      `json:"total_file_seconds"` // Sum of the times of the files
+	Results   []reportFile `json:"results"`
+	mu        sync.Mutex
+	startTime time.Time
+}
+
+// newBatchReport starts the report of a batch.
+func newBatchReport(flags []string) *batchReport {
+	now := time.Now()
+	return &batchReport{Version: toolVersion(), Flags: flags, Started: now.UTC(), Results: []reportFile{}, startTime: now}
+}
+
+// add adds the result of a file.
+//
+// Parameters:
+//   - file: Path of the input
+//   - count: The count, if there is no error
+//   - err: The error of the count, if any
+//   - in: The input, its zero value if it could not be read
+//   - elapsed: The time taken by reading and counting the input
+func (r *batchReport) add(file, count string, err error, in inputInfo, elapsed time.Duration) {
+	e := reportFile{File: file, Count: count, Length: in.length, Seconds: elapsed.Seconds(), DupOf: in.dup}
+	if in.sum != nil {
+		e.SHA256 = hex.EncodeToString(in.sum)
+	}
+	if err != nil {
+		e.Error, _, _ = strings.Cut(err.Error(), "\n") // One line, without the snippet
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Results = append(r.Results, e)
+	r.Files++
+	if err != nil {
+		r.Failures++
+	}
+	if in.dup != "" {
+		r.Dups++
+	}
+	r.Digits += int64(in.length)
+	r.FileTime += elapsed.Seconds()
+}
+
+// save finishes the report and writes it as indented JSON.
+//
+// Parameters:
+//   - path: Path of the report file
+//
+// Returns:
+//   - error: An error if the file can not be written
+func (r *batchReport) save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Seconds = time.Since(r.startTime).Seconds()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, 0x0A), 0o644)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// resultCache is the directory of the on-disk result cache, bounded in size
+// by --result-cache-size, nil if the cache is disabled. It is set from the
+// --result-cache flag.
+var resultCache *cacheDir
+
+// resultVersion is part of every result cache key. It is bumped whenever a
+// fix changes the counts of some inputs, so that the entries stored by
+// earlier versions are not answered: version 2 counts wildcards under the
+// scheme of --zero-based and --alphabet.
+const resultVersion = "2"
+
+// resultKey derives the cache key of a count from the input and the options
+// that affect the result.
+//
+// SHA-256 is used because it is in the standard library and, with hardware
+// support, hashes at several GB/s, far faster than counting.
+//
+// Parameters:
+//   - p: The input
+//   - options: A canonical description of the options, e.g. the alphabet
+//
+// Returns:
+//   - string: The key as a hex string
+func resultKey(p []byte, options string) string {
+	h := sha256.New()
+	h.Write([]byte(resultVersion))
+	h.Write([]byte{0})
+	h.Write([]byte(options))
+	h.Write([]byte{0})
+	h.Write(p)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadResult returns the cached count of a key.
+//
+// Parameters:
+//   - key: The cache key, see resultKey
+//
+// Returns:
+//   - string: The count in decimal, or "≥ k" for a count of --at-least
+//     that reached the threshold k, which is part of the key
+//   - bool: Whether a valid entry was found
+func loadResult(key string) (string, bool) {
+	if resultCache == nil {
+		return "", false
+	}
+	data, err := resultCache.read(key)
+	if err != nil || len(data) < 2 || data[len(data)-1] != '\n' {
+		return "", false
+	}
+	data = data[:len(data)-1]
+	digits := bytes.TrimPrefix(data, []byte("≥ "))
+	if len(digits) == 0 {
+		return "", false
+	}
+	for _, b := range digits {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return "", false
+		}
+	}
+	return string(data), true
+}
+
+// storeResult writes a count to the result cache, evicting the least
+// recently used counts if it is over its size. The cache is best effort, so
+// errors are ignored.
+//
+// Parameters:
+//   - key: The cache key, see resultKey
+//   - count: The count in decimal, or "≥ k" (see loadResult)
+func storeResult(key, count string) {
+	if resultCache == nil {
+		return
+	}
+	resultCache.write(key, []byte(count), []byte{'\n'})
+}
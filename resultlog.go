@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// resultLog is the CSV log of --log-results: one row per count, appended to
+// a file that several processes may share.
+type resultLog struct {
+	path string
+}
+
+// resultLogHeader is the header of a new result log.
+var resultLogHeader = []string{"timestamp", "file", "sha256", "count", "duration", "error"}
+
+// openResultLog checks that a result log can be appended to, creating it if
+// needed.
+//
+// Parameters:
+//   - path: Path of the CSV file
+//
+// Returns:
+//   - *resultLog: The log
+//   - error: An error if the file can not be opened for appending
+func openResultLog(path string) (*resultLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return &resultLog{path: path}, nil
+}
+
+// append appends the row of a count. The file is opened for every row and
+// locked while it is written (see lockFile), so the rows of concurrent
+// workers and processes do not interleave; the header is written by
+// whoever appends to an empty file first.
+//
+// Parameters:
+//   - file: Path of the input
+//   - sum: SHA-256 of the input, nil if it could not be read
+//   - count: The count, if there is no error
+//   - countErr: The error of the count, if any
+//   - elapsed: The time taken by reading and counting the input
+//
+// Returns:
+//   - error: An error if the row can not be written
+func (l *resultLog) append(file string, sum []byte, count string, countErr error, elapsed time.Duration) error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("locking %s: %w", l.path, err)
+	}
+	defer unlockFile(f)
+
+	w := csv.NewWriter(f)
+	if fi, err := f.Stat(); err == nil && fi.Size() == 0 {
+		w.Write(resultLogHeader)
+	}
+	var msg string
+	if countErr != nil {
+		msg, _, _ = strings.Cut(countErr.Error(), "\n") // One line, without the snippet
+	}
+	w.Write([]string{time.Now().UTC().Format(time.RFC3339), file, hex.EncodeToString(sum), count, fmt.Sprintf("%.6f", elapsed.Seconds()), msg})
+	w.Flush()
+	return w.Error()
+}
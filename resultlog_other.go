@@ -0,0 +1,21 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build !unix && !windows
+
+package main
+
+import "os"
+
+// lockFile does nothing on platforms without file locks: rows appended by
+// concurrent processes may interleave there.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile releases the lock of lockFile.
+func unlockFile(f *os.File) error {
+	return nil
+}
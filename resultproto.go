@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// resultDesc describes the Result message of proto/decodeways.proto. Like
+// countServiceDesc it is written by hand rather than generated, and the
+// messages are dynamicpb messages of it, which marshal like generated ones.
+var resultDesc = func() protoreflect.MessageDescriptor {
+	field := func(number int32, name string, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			JsonName: proto.String(name),
+		}
+	}
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	flags := field(8, "flags", str)
+	flags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("proto/decodeways.proto"),
+		Package: proto.String("decodeways.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Result"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field(1, "file", str),
+				field(2, "count", str),
+				field(3, "error", str),
+				field(4, "sha256", descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				field(5, "length", descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field(6, "version", str),
+				field(7, "algorithm", str),
+				flags,
+				field(9, "duplicate_of", str),
+			},
+		}},
+	}, new(protoregistry.Files))
+	if err != nil {
+		panic(err) // The descriptor is constant
+	}
+	return fd.Messages().ByName("Result")
+}()
+
+// resultMessage returns the Result message of the record of a count.
+func resultMessage(r resultRecord) proto.Message {
+	m := dynamicpb.NewMessage(resultDesc)
+	fields := resultDesc.Fields()
+	set := func(name, s string) {
+		if s != "" {
+			m.Set(fields.ByName(protoreflect.Name(name)), protoreflect.ValueOfString(s))
+		}
+	}
+	set("file", r.File)
+	set("count", r.Count)
+	set("error", r.Error)
+	if sum, err := hex.DecodeString(r.SHA256); err == nil && len(sum) > 0 {
+		m.Set(fields.ByName("sha256"), protoreflect.ValueOfBytes(sum))
+	}
+	m.Set(fields.ByName("length"), protoreflect.ValueOfInt64(int64(r.Length)))
+	set("version", r.Version)
+	set("algorithm", r.Algorithm)
+	flags := m.Mutable(fields.ByName("flags")).List()
+	for _, f := range r.Flags {
+		flags.Append(protoreflect.ValueOfString(f))
+	}
+	set("duplicate_of", r.DupOf)
+	return m
+}
+
+// protoFormatter writes the records of counts as Result messages of
+// proto/decodeways.proto, each prefixed with its length as a varint, so a
+// stream of them can be split again (see protodelim).
+type protoFormatter struct {
+	w io.Writer
+}
+
+// Format implements Formatter. Only the records of counts have a message.
+func (f *protoFormatter) Format(v any) error {
+	r, ok := v.(resultRecord)
+	if !ok {
+		return fmt.Errorf("--format proto only supports the results of counts, not %T records", v)
+	}
+	_, err := protodelim.MarshalTo(f.w, resultMessage(r))
+	return err
+}
+
+// Flush implements Formatter. The messages are not buffered.
+func (f *protoFormatter) Flush() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+
+	"task1/decodeways"
+)
+
+// maxCode is the largest code supported for generalized alphabets.
+const maxCode = 999999999999999999
+
+// codeRange describes an alphabet whose codes are the numbers lo..hi
+// written in decimal without leading zeros.
+type codeRange struct {
+	lo, hi int
+}
+
+// newCodeRange returns the code range of an alphabet of k letters.
+//
+// The letters are numbered 1..k, or 0..k-1 if zeroBased is set (e.g. the
+// mapping 'A' -> 0, ..., 'Z' -> 25, where every digit including 0 is a letter
+// on its own).
+//
+// Parameters:
+//   - k: Number of letters in the alphabet
+//   - zeroBased: Whether the first letter is 0 rather than 1
+//
+// Returns:
+//   - codeRange: The code range of the alphabet
+//   - error: An error if k is not positive or too large
+func newCodeRange(k int, zeroBased bool) (codeRange, error) {
+	lo := 1
+	if zeroBased {
+		lo = 0
+	}
+	if k < 1 || k > maxCode {
+		return codeRange{}, fmt.Errorf("invalid alphabet size %d", k)
+	}
+	return codeRange{lo: lo, hi: lo + k - 1}, nil
+}
+
+// width returns the number of digits of the longest code.
+func (r codeRange) width() int {
+	w := 1
+	for v := r.hi; v >= 10; v /= 10 {
+		w++
+	}
+	return w
+}
+
+// scheme returns the scheme of a code range with codes of up to two digits.
+//
+// This keeps the Fibonacci cluster shortcut applicable: pairs form clusters
+// exactly as for the standard alphabet, only the range boundaries move.
+func (r codeRange) scheme() decodeways.Scheme {
+	var s decodeways.Scheme
+	for d := r.lo; d <= 9 && d <= r.hi; d++ {
+		s.Single |= 1 << d
+	}
+	for v := 10; v <= r.hi && v <= 99; v++ {
+		s.Pair[v/10] |= 1 << (v % 10)
+	}
+	return s
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// letterModel holds natural-log probabilities of the letters A-Z.
+// A letter with zero probability has log-probability -Inf and never
+// appears in a scored decoding.
+type letterModel [26]float64
+
+// englishFrequencies are the relative frequencies (in percent) of the letters
+// A-Z in English text.
+var englishFrequencies = [26]float64{
+	8.167, 1.492, 2.782, 4.253, 12.702, 2.228, 2.015, 6.094, 6.966, // A-I
+	0.153, 0.772, 4.025, 2.406, 6.749, 7.507, 1.929, 0.095, 5.987, // J-R
+	6.327, 9.056, 2.758, 0.978, 2.360, 0.150, 1.974, 0.074, // S-Z
+}
+
+// englishModel is the built-in letter model used for scoring decodings.
+var englishModel = newLetterModel(englishFrequencies)
+
+// newLetterModel builds a letter model from relative letter weights.
+// The weights do not have to sum to anything in particular, they are normalized.
+func newLetterModel(w [26]float64) letterModel {
+	sum := 0.0
+	for _, v := range w {
+		sum += v
+	}
+	var m letterModel
+	for i, v := range w {
+		m[i] = math.Log(v / sum)
+	}
+	return m
+}
+
+// loadLetterModel reads letter weights from a file.
+//
+// Each non-empty line holds a letter and its weight separated by whitespace,
+// e.g. "E 12.7". Lines starting with '#' are ignored. Letters that are not
+// listed get zero weight.
+//
+// Parameters:
+//   - filename: Path to the frequency file
+//
+// Returns:
+//   - letterModel: The normalized model
+//   - error: An error if the file can not be read or parsed
+func loadLetterModel(filename string) (letterModel, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return letterModel{}, err
+	}
+	defer fh.Close()
+
+	var w [26]float64
+	total := 0.0
+	sc := bufio.NewScanner(fh)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) != 2 || len(fields[0]) != 1 {
+			return letterModel{}, fmt.Errorf("line %d: expected \"<letter> <weight>\"", line)
+		}
+		c := fields[0][0] &^ 0x20 // Upper case
+		if c < 'A' || c > 'Z' {
+			return letterModel{}, fmt.Errorf("line %d: %q is not a letter", line, fields[0])
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || v < 0 {
+			return letterModel{}, fmt.Errorf("line %d: invalid weight %q", line, fields[1])
+		}
+		w[c-'A'] = v
+		total += v
+	}
+	if err := sc.Err(); err != nil {
+		return letterModel{}, err
+	}
+	if total == 0 {
+		return letterModel{}, errors.New("no letter has a positive weight")
+	}
+	return newLetterModel(w), nil
+}
+
+// scoredDecoding is a decoding together with its log-probability score.
+type scoredDecoding struct {
+	Text  string
+	Score float64
+}
+
+// scoreRecord is the record of a decoding of --best or --top.
+type scoreRecord struct {
+	Decoding string  `json:"decoding"`
+	Score    float64 `json:"score"` // Log-probability under the letter model
+
+	best bool // Whether it is the decoding of --best, printed alone
+}
+
+// plain implements plainRecord: the decoding alone for --best, like a count,
+// "<score>\t<decoding>" for --top.
+func (r scoreRecord) plain() string {
+	if r.best {
+		return r.Decoding
+	}
+	return fmt.Sprintf("%.4f\t%s\n", r.Score, r.Decoding)
+}
+
+// bestDecoding finds the single most probable decoding of a digit string.
+//
+// It is the k = 1 case of topDecodings, which boils down to a Viterbi-style
+// max-product DP.
+//
+// Returns:
+//   - string: The highest-scoring decoding
+//   - float64: Its log-probability score
+//   - error: An error if the input is invalid or has no decoding with non-zero probability
+func bestDecoding(p []byte, m letterModel) (string, float64, error) {
+	d, err := topDecodings(p, m, 1)
+	if err != nil {
+		return "", 0, err
+	}
+	return d[0].Text, d[0].Score, nil
+}
+
+// topDecodings finds the k most probable decodings of a digit string.
+//
+// Every decoding is scored as the sum of the log-probabilities of its letters.
+// The k best scores of a prefix of length i are the k best of extending the
+// k best prefixes i-1 with a one-digit letter and the k best prefixes i-2
+// with a two-digit letter. Both candidate lists are already sorted, so they
+// are simply merged.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to decode
+//   - m: Letter model used for scoring
+//   - k: Number of decodings to return (1 to 65535)
+//
+// Returns:
+//   - []scoredDecoding: Up to k decodings ordered by descending score
+//   - error: An error if the input is invalid or has no decoding with non-zero probability
+//
+// Time Complexity: O(n * k), Space Complexity: O(n * k) for the back pointers
+func topDecodings(p []byte, m letterModel, k int) ([]scoredDecoding, error) {
+	n := len(p)
+	if n == 0 {
+		return nil, errors.New("empty string")
+	}
+	if k < 1 || k > math.MaxUint16 {
+		return nil, fmt.Errorf("k must be between 1 and %d", math.MaxUint16)
+	}
+
+	// back[i*k+j] describes the j-th best decoding of the prefix of length i+1:
+	// the rank of the extended prefix decoding shifted left by one, with the
+	// lowest bit set when the last letter has two digits.
+	back := make([]uint32, n*k)
+
+	// Sorted scores of the prefixes i-1 and i; the empty prefix has score 0
+	prev2, prev1 := []float64{}, []float64{0}
+	cur := make([]float64, 0, k)
+
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return nil, fmt.Errorf("encountered non-digit character at pos. %d", i)
+		}
+
+		// Candidate lists: one-digit letter after prefix i, two-digit after i-1
+		var one, two []float64
+		var s1, s2 float64
+		if b != 0x30 {
+			one, s1 = prev1, m[b-0x31]
+		}
+		if i > 0 {
+			if v := int(p[i-1]-0x30)*10 + int(b-0x30); v >= 10 && v <= 26 {
+				two, s2 = prev2, m[v-1]
+			}
+		}
+
+		// Merge the two sorted candidate lists keeping the k best
+		cur = cur[:0]
+		bi := back[i*k : i*k+k]
+		x, y := 0, 0
+		for len(cur) < k {
+			var sx, sy float64 = math.Inf(-1), math.Inf(-1)
+			if x < len(one) {
+				sx = one[x] + s1
+			}
+			if y < len(two) {
+				sy = two[y] + s2
+			}
+			if math.IsInf(sx, -1) && math.IsInf(sy, -1) {
+				break
+			}
+			if sx >= sy {
+				bi[len(cur)] = uint32(x) << 1
+				cur = append(cur, sx)
+				x++
+			} else {
+				bi[len(cur)] = uint32(y)<<1 | 1
+				cur = append(cur, sy)
+				y++
+			}
+		}
+		prev2, prev1, cur = prev1, cur, prev2[:0]
+	}
+	if len(prev1) == 0 {
+		return nil, errors.New("string has no decoding with non-zero probability")
+	}
+
+	// Walk the back pointers from the end to reconstruct the letters
+	res := make([]scoredDecoding, len(prev1))
+	out := make([]byte, 0, n)
+	for j := range res {
+		out = out[:0]
+		for i, r := n-1, j; i >= 0; {
+			e := back[i*k+r]
+			v := int(p[i] - 0x30)
+			if e&1 == 1 {
+				v += int(p[i-1]-0x30) * 10
+				i -= 2
+			} else {
+				i--
+			}
+			out = append(out, byte('A'+v-1))
+			r = int(e >> 1)
+		}
+		for a, b := 0, len(out)-1; a < b; a, b = a+1, b-1 {
+			out[a], out[b] = out[b], out[a]
+		}
+		res[j] = scoredDecoding{Text: string(out), Score: prev1[j]}
+	}
+	return res, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// selftestVector is an input of the selftest with its known answer.
+type selftestVector struct {
+	name  string
+	input func() []byte
+	want  string // Count in decimal, "sha256:<hex>" of it, or "error: <part of the message>"
+}
+
+// digits returns the input of a literal digit string.
+func digits(s string) func() []byte {
+	return func() []byte { return []byte(s) }
+}
+
+// repeat returns the input of a digit string repeated n times.
+func repeat(s string, n int) func() []byte {
+	return func() []byte { return bytes.Repeat([]byte(s), n) }
+}
+
+// selftestVectors is the golden corpus of the selftest. The answers were
+// computed independently of this program: the small ones with the classic
+// DP, the large ones from their closed forms (F(n+1) for n ones, 2^n for n
+// separate clusters of size 1).
+var selftestVectors = []selftestVector{
+	{"single digit", digits("1"), "1"},
+	{"10", digits("10"), "1"},
+	{"20", digits("20"), "1"},
+	{"27", digits("27"), "1"},
+	{"12", digits("12"), "2"},
+	{"226", digits("226"), "3"},
+	{"11106", digits("11106"), "2"},
+	{"2101", digits("2101"), "1"},
+	{"1201234", digits("1201234"), "3"},
+	{"alternating tens", digits("101010101010"), "1"},
+	{"ten ones", digits("1111111111"), "89"},
+	{"mixed", digits("2611055971756562"), "4"},
+	{"100", digits("100"), "error: can not be attached to 0"},
+	{"leading zero", digits("0"), "error: string starts with 0"},
+	{"30", digits("30"), "error: can not be attached to 3"},
+	{"empty", digits(""), "error: empty string"},
+	{"non-digit", digits("12a"), "error: non-digit character"},
+	{"repeated 2101", repeat("2101", 100), "633825300114114700748351602688"},
+	{"cluster of 1000", repeat("1", 1000), "sha256:23d7c70a9b8b650fe90986beec206ab987c6467aeda28aa8cde8c80ade009f02"},
+	{"cluster of 100000", repeat("12", 50000), "sha256:c8cd6ee573d819db4ab007a0c8809d9fab01dc5878572450bbb82e846a3a37ff"},
+	{"many small clusters", repeat("127", 100000), "sha256:3c1bef061d8b167e2d3466c99a529de8474d6d1bd8a2bb46e173a533c19da918"},
+	{"giant cluster over chunks", repeat("1", 3<<20), "sha256:263b7ec36540c04e4d5fc18ef3ae45cbd23364547b9ca17e8ea5f54c95115d17"},
+	{"zeros over chunks", repeat("1210", 1<<20), "sha256:a3d7bd2854ec321440467462e63694fe5ef873f5a417512e0c3a1ccaf203fd5c"},
+}
+
+// check runs a vector through a counter.
+//
+// Parameters:
+//   - c: The counter, with the standard options
+//
+// Returns:
+//   - error: An error describing the mismatch, nil if the answer is right
+func (v selftestVector) check(c *counter) error {
+	x, err := c.countInput(v.input())
+	if msg, ok := strings.CutPrefix(v.want, "error: "); ok {
+		if err == nil {
+			return fmt.Errorf("got %s, want an error", abbreviate(x))
+		} else if !strings.Contains(err.Error(), msg) {
+			return fmt.Errorf("got error %q, want one containing %q", err, msg)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("got error %q, want %s", err, abbreviate(v.want))
+	}
+	got := x
+	if strings.HasPrefix(v.want, "sha256:") {
+		got = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(x)))
+	}
+	if got != v.want {
+		return fmt.Errorf("got %s, want %s", abbreviate(got), abbreviate(v.want))
+	}
+	return nil
+}
+
+// abbreviate shortens a long count for messages.
+func abbreviate(x string) string {
+	if len(x) <= 40 {
+		return x
+	}
+	return fmt.Sprintf("%s...%s (%d digits)", x[:8], x[len(x)-8:], len(x))
+}
+
+// selftestRecord is the record of a vector of the golden corpus.
+type selftestRecord struct {
+	Name    string  `json:"name"`
+	Passed  bool    `json:"passed"`
+	Error   string  `json:"error,omitempty"`
+	Seconds float64 `json:"seconds"`
+
+	elapsed time.Duration // The time of the count
+	verbose bool          // Whether passing vectors are printed too (-v)
+}
+
+// plain implements plainRecord: "FAIL <name>: <error>" for a failure, and
+// with -v "ok   <name> (<time>)" for a vector that passes.
+func (r selftestRecord) plain() string {
+	switch {
+	case !r.Passed:
+		return fmt.Sprintf("FAIL %s: %s\n", r.Name, r.Error)
+	case r.verbose:
+		return fmt.Sprintf("ok   %s (%v)\n", r.Name, r.elapsed.Round(time.Microsecond))
+	}
+	return ""
+}
+
+// runSelftest implements the "selftest" subcommand: it counts the golden
+// corpus with the standard options and reports the vectors that fail, so a
+// deployed binary can be sanity-checked.
+//
+// Usage: decode-ways selftest [-v] [--format json]
+//
+// Returns:
+//   - int: The process exit code, 1 if a vector fails
+func runSelftest(args []string) int {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "report every vector, not only the failures")
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways selftest [-v] [--format json]")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+
+	c, err := newCounter(*addCountFlags(flag.NewFlagSet("", flag.ContinueOnError)))
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	failed := 0
+	for _, v := range selftestVectors {
+		start := time.Now()
+		r := selftestRecord{Name: v.name, Passed: true, verbose: *verbose}
+		if err := v.check(c); err != nil {
+			failed++
+			r.Passed, r.Error = false, err.Error()
+		}
+		r.elapsed = time.Since(start)
+		r.Seconds = r.elapsed.Seconds()
+		if err := f.Format(r); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return exitIO
+		}
+	}
+	if code := formatAll(f); code != 0 {
+		return code
+	}
+	// Below the vectors, or on stderr not to mix with the records of a format
+	w := os.Stderr
+	if *format == "plain" {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, "%d of %d vectors passed\n", len(selftestVectors)-failed, len(selftestVectors))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,467 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// server answers count requests over HTTP.
+type server struct {
+	c     *counter
+	cache *lruCache   // Recent counts by input hash, nil if disabled
+	store *boltCache  // Persistent counts by input hash, nil if disabled
+	tls   *tls.Config // TLS configuration of all endpoints, nil for plain text
+	keys  apiKeys     // Accepted API keys, nil if authentication is disabled
+
+	quotas *quotaTracker // Usage and quotas of the API keys, nil without keys
+
+	codings []string     // Content codings of responses by preference, nil to not compress
+	jobs    *jobQueue    // Asynchronous jobs of /v1/jobs
+	compute *computePool // Workers of the counts, nil for no limit
+
+	limiter  *rateLimiter  // Per-client rate limit, nil if disabled
+	maxInput int64         // Maximum input size in bytes, 0 for no limit
+	maxBatch int           // Maximum number of inputs of a batch request, 0 for no limit
+	timeout  time.Duration // Deadline of a count, 0 for none
+
+	inflight sync.WaitGroup // Counts running, including those past their deadline
+	closing  chan struct{}  // Closed when the server shuts down
+}
+
+// httpError is the body of an HTTP error response.
+type httpError struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+// writeError replies to an HTTP request with a JSON error, e.g.
+// {"status":413,"error":"input larger than 1048576 bytes"}.
+//
+// Parameters:
+//   - w: The response writer
+//   - status: The HTTP status code
+//   - msg: The error message
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(httpError{Status: status, Error: msg})
+}
+
+// count counts an input, answering hot repeated queries from the LRU cache,
+// and counts of earlier runs from the persistent store. Counts missing from
+// both take a worker of the compute pool first (see computePool).
+//
+// Parameters:
+//   - ctx: The context of the count, ending its wait for a compute worker
+//   - p: The digit string
+//   - refuse: Whether to refuse the count with errBusy if the compute queue
+//     is full
+//
+// Returns:
+//   - string: The count in decimal
+//   - bool: Whether the count came from the cache
+//   - error: An error if the input is invalid, errBusy, or ctx.Err()
+func (sv *server) count(ctx context.Context, p []byte, refuse bool) (string, bool, error) {
+	sv.inflight.Add(1)
+	defer sv.inflight.Done()
+	key := resultKey(p, sv.c.options)
+	if r, ok := sv.cache.get(key); ok {
+		recordMetrics(int64(len(p)), true, nil)
+		return r, true, nil
+	}
+	if r, ok := sv.store.get(key); ok {
+		sv.cache.put(key, r)
+		recordMetrics(int64(len(p)), true, nil)
+		return r, true, nil
+	}
+	release, err := sv.compute.acquire(ctx, refuse)
+	if err != nil {
+		return "", false, err
+	}
+	defer release()
+	r, err := sv.c.countInput(p)
+	if err != nil {
+		return "", false, err
+	}
+	sv.cache.put(key, r)
+	if err := sv.store.put(key, r); err != nil {
+		slog.Error("storing count failed", "err", err)
+	}
+	return r, false, nil
+}
+
+// countWithin counts an input like count, but gives up waiting once ctx is
+// done. Counting can not be interrupted, so a count that missed its deadline
+// still runs to the end and goes into the cache, where a retry finds it.
+//
+// Parameters:
+//   - ctx: The context of the request, with the deadline of --request-timeout
+//   - p: The digit string
+//
+// Returns:
+//   - string: The count in decimal
+//   - bool: Whether the count came from the cache
+//   - error: An error if the input is invalid, errBusy, or ctx.Err()
+func (sv *server) countWithin(ctx context.Context, p []byte) (string, bool, error) {
+	type result struct {
+		x   string
+		hit bool
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		start := time.Now()
+		x, hit, err := sv.count(ctx, p, true)
+		if !errors.Is(err, errBusy) && (err == nil || err != ctx.Err()) {
+			sv.chargeCount(ctx, int64(len(p)), time.Since(start)) // Refused counts are free
+		}
+		done <- result{x, hit, err}
+	}()
+	select {
+	case r := <-done:
+		return r.x, r.hit, r.err
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+}
+
+// withTimeout returns the context of a request with the deadline of
+// --request-timeout, if any.
+func (sv *server) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if sv.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, sv.timeout)
+}
+
+// handleCount implements POST /count: the request body is the digit string,
+// the response body the number of decodings followed by a newline. Errors get
+// a JSON body (see writeError): 400 for invalid inputs, 413 for inputs over
+// --max-input, 503 when the compute queue is full and 504 for counts over
+// --request-timeout. The X-Cache header
+// tells whether the count came from the cache.
+func (sv *server) handleCount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body := r.Body
+	if sv.maxInput > 0 {
+		body = http.MaxBytesReader(w, body, sv.maxInput)
+	}
+	p, err := io.ReadAll(body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("input larger than %d bytes", tooLarge.Limit))
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Error reading request: %v", err))
+		return
+	}
+	ctx, cancel := sv.withTimeout(r.Context())
+	defer cancel()
+	start := time.Now()
+	x, hit, err := sv.countWithin(ctx, p)
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.Warn("count timed out", "bytes", len(p), "timeout", sv.timeout)
+		writeError(w, http.StatusGatewayTimeout, fmt.Sprintf("count not finished within %v", sv.timeout))
+		return
+	} else if errors.Is(err, errBusy) {
+		slog.Warn("count refused", "bytes", len(p), "err", err)
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	} else if err != nil {
+		slog.Info("invalid input", "bytes", len(p), "err", err)
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Error decoding: %v", err))
+		return
+	}
+	slog.Info("count", "bytes", len(p), "cache_hit", hit, "duration", time.Since(start))
+	if hit {
+		w.Header().Set("X-Cache", "hit")
+	} else {
+		w.Header().Set("X-Cache", "miss")
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, x)
+}
+
+// routes returns the handler of all endpoints.
+func (sv *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/count", sv.handleCount)
+	mux.HandleFunc("/v1/count/batch", sv.handleBatch)
+	mux.HandleFunc("POST /v1/jobs", sv.handleSubmitJob)
+	mux.HandleFunc("GET /v1/jobs/{id}", sv.handleJob)
+	mux.HandleFunc("GET /v1/usage", sv.handleUsage)
+	mux.HandleFunc("/ws", sv.handleWS)
+	mux.Handle("/debug/vars", expvar.Handler()) // See metrics.go
+	return sv.authenticate(sv.limit(sv.meter(sv.compress(mux))))
+}
+
+// runServe implements the "serve" subcommand.
+//
+// Usage: decode-ways serve [--addr :8080] [--cache-size 64M] [--grpc-addr :9090]
+//
+// Returns:
+//   - int: The process exit code
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "`address` to listen on")
+	cacheSize := fs.String("cache-size", "64M", "`size` of the LRU cache of recent counts (0 to disable)")
+	storePath := fs.String("store", "", "keep counts across restarts in the bbolt database `file`")
+	storeTTL := fs.Duration("store-ttl", 0, "expire counts in --store after this time (0 to keep them)")
+	storeSize := fs.String("store-size", "1G", "evict the oldest counts when --store exceeds `size` (0 for no limit)")
+	grpcAddr := fs.String("grpc-addr", "", "also serve the gRPC service of proto/decodeways.proto on `address`")
+	cleartext := fs.Bool("h2c", false, "accept HTTP/2 without TLS (h2c) on --addr, e.g. for gRPC behind a mesh proxy")
+	maxInput := fs.String("max-input", "256M", "reject inputs larger than `size` (0 for no limit)")
+	maxBatch := fs.Int("max-batch", 10000, "reject batch requests of more than `n` inputs (0 for no limit)")
+	computeWorkers := fs.Int("compute-workers", runtime.NumCPU(), "number of `counts` computed at once, whatever the number of connections (0 for no limit)")
+	computeQueue := fs.Int("compute-queue", 100, "number of `counts` waiting for a compute worker, 503 above it")
+	timeout := fs.Duration("request-timeout", time.Minute, "give up waiting for a count after this time (0 for no limit)")
+	qps := fs.Float64("rate", 0, "limit every client (API key or IP) to `qps` requests per second (0 for no limit)")
+	burst := fs.Int("burst", 10, "number of requests a client may make at once under --rate")
+	tlsCert := fs.String("tls-cert", "", "serve HTTPS and gRPC over TLS with the PEM certificate `file`")
+	tlsKey := fs.String("tls-key", "", "PEM private key `file` of --tls-cert")
+	tlsReload := fs.Duration("tls-reload", 0, "check the certificate files for rotation at this interval (0 to never reload)")
+	drain := fs.Duration("drain-timeout", 30*time.Second, "time given to requests in flight to finish on SIGTERM")
+	jobsDir := fs.String("jobs-dir", "", "spool the inputs and the journal of /v1/jobs to `dir`, where jobs survive restarts (default a temporary directory)")
+	jobWorkers := fs.Int("job-workers", 1, "number of `jobs` of /v1/jobs counted at once")
+	jobQueueSize := fs.Int("job-queue", 1000, "maximum number of queued `jobs`, 503 above it")
+	maxJobInput := fs.String("max-job-input", "4G", "reject job inputs larger than `size` (0 for no limit)")
+	jobTTL := fs.Duration("job-ttl", 24*time.Hour, "keep the results of finished jobs for this time")
+	codings := fs.String("compress", "zstd,gzip", "compress responses with the first of these `codings` the client accepts (empty to disable)")
+	quotaBytes := fs.String("quota-bytes", "0", "limit every API key to `size` of input per --quota-window (0 for no limit)")
+	quotaCPU := fs.Duration("quota-cpu", 0, "limit every API key to this time spent counting per --quota-window (0 for no limit)")
+	quotaWindow := fs.Duration("quota-window", 24*time.Hour, "length of the window of the quotas")
+	keyFile := fs.String("api-keys", "", "require one of the API keys in `file` (one per line, also $"+apiKeysEnv+")")
+	countOpts := addCountFlags(fs)
+	logOpts := addLogFlags(fs, "info")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways serve [--addr :8080] [--cache-size 64M] [--grpc-addr :9090]")
+		fmt.Fprintln(os.Stderr, "Example: curl --data-binary @test.txt localhost:8080/count")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+	if err := setupLogging(*logOpts); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+
+	size := int64(0)
+	if *cacheSize != "0" {
+		var err error
+		if size, err = parseSize(*cacheSize); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --cache-size: %v\n", trError(err))))
+			return 1
+		}
+	}
+	c, err := newCounter(*countOpts)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	sv := &server{
+		c:        c,
+		cache:    newLRUCache(size),
+		limiter:  newRateLimiter(*qps, *burst),
+		timeout:  *timeout,
+		maxBatch: *maxBatch,
+		closing:  make(chan struct{}),
+	}
+	if *maxInput != "0" {
+		if sv.maxInput, err = parseSize(*maxInput); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --max-input: %v\n", trError(err))))
+			return 1
+		}
+	}
+	if *storePath != "" {
+		n := int64(0)
+		if *storeSize != "0" {
+			if n, err = parseSize(*storeSize); err != nil {
+				fmt.Fprint(os.Stderr, diagnostic(tr("Error: --store-size: %v\n", trError(err))))
+				return 1
+			}
+		}
+		if sv.store, err = openBoltCache(*storePath, *storeTTL, n); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: opening %s: %v\n", *storePath, trError(err))))
+			return 1
+		}
+		defer sv.store.Close()
+	}
+	if sv.tls, err = tlsConfig(*tlsCert, *tlsKey, *tlsReload); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	if sv.codings, err = parseCodings(*codings); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --compress: %v\n", trError(err))))
+		return 1
+	}
+	if sv.keys, err = loadAPIKeys(*keyFile); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: loading API keys: %v\n", trError(err))))
+		return 1
+	}
+	var def quota
+	if *quotaBytes != "0" {
+		if def.bytes, err = parseSize(*quotaBytes); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --quota-bytes: %v\n", trError(err))))
+			return 1
+		}
+	}
+	def.cpu = *quotaCPU
+	if sv.keys == nil && (def.bytes > 0 || def.cpu > 0) {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --quota-bytes and --quota-cpu need --api-keys, as quotas are per API key\n")))
+		return 1
+	}
+	if *quotaWindow <= 0 {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --quota-window must be positive\n")))
+		return 1
+	}
+	sv.quotas = newQuotaTracker(sv.keys, def, *quotaWindow)
+	if *computeWorkers < 0 || *computeQueue < 0 {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --compute-workers and --compute-queue must not be negative\n")))
+		return 1
+	}
+	sv.compute = newComputePool(*computeWorkers, *computeQueue)
+
+	if *jobWorkers < 1 {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --job-workers must be positive\n")))
+		return 1
+	}
+	maxJob := int64(0)
+	if *maxJobInput != "0" {
+		if maxJob, err = parseSize(*maxJobInput); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: --max-job-input: %v\n", trError(err))))
+			return 1
+		}
+	}
+	dir := *jobsDir
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "decode-ways-jobs-"); err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return 1
+		}
+		defer os.RemoveAll(dir)
+	}
+	if sv.jobs, err = newJobQueue(dir, *jobQueueSize, maxJob, *jobTTL); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --jobs-dir: %v\n", trError(err))))
+		return 1
+	}
+	defer sv.jobs.Close()
+
+	if *cleartext && sv.tls != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: --h2c is for plain text, with --tls-cert HTTP/2 is negotiated anyway\n")))
+		return 1
+	}
+	hs, err := newHTTPServer(sv, *addr, *cleartext)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	hs.RegisterOnShutdown(func() { close(sv.closing) })
+	var g *grpc.Server
+	errs := make(chan error, 2)
+	if *grpcAddr != "" {
+		l, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+			return 1
+		}
+		g = newGRPCServer(sv)
+		slog.Info("listening for gRPC", "addr", *grpcAddr)
+		go func() { errs <- g.Serve(l) }()
+	}
+	for i := 0; i < *jobWorkers; i++ {
+		go sv.jobs.work(sv)
+	}
+	go sv.jobs.expire(sv.closing)
+	slog.Info("listening", "addr", *addr, "tls", sv.tls != nil, "h2c", *cleartext)
+	go func() {
+		if sv.tls != nil {
+			errs <- hs.ListenAndServeTLS("", "") // The certificate comes from TLSConfig
+		} else {
+			errs <- hs.ListenAndServe()
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-errs:
+		slog.Error("serving failed", "err", err)
+		return 1
+	case <-sig:
+	}
+	signal.Stop(sig) // A second signal kills the process at once
+	slog.Info("shutting down", "drain", *drain)
+	if err := sv.shutdown(hs, g, *drain); err != nil {
+		slog.Error("shutdown failed", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// shutdown stops the server gracefully: the listeners are closed at once,
+// WebSocket clients are sent a close message, and the requests in flight are
+// given until the drain timeout to finish. Counts that missed their request
+// deadline but are still running are waited for as well, since they hold
+// the CPU and their results would be lost.
+//
+// Parameters:
+//   - hs: The HTTP server
+//   - g: The gRPC server, nil if not enabled
+//   - drain: The drain timeout
+//
+// Returns:
+//   - error: An error if the requests did not finish in time
+func (sv *server) shutdown(hs *http.Server, g *grpc.Server, drain time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if g != nil {
+			g.GracefulStop()
+		}
+		sv.inflight.Wait()
+	}()
+	err := hs.Shutdown(ctx)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if g != nil {
+			g.Stop()
+		}
+		return fmt.Errorf("requests still running after %v", drain)
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// snippetRadius is the number of characters shown on either side of an
+// invalid character.
+const snippetRadius = 16
+
+// visible returns the printable form of a byte in a snippet: control
+// characters and non-ASCII bytes are escaped, so a stray newline or letter
+// is plain to see.
+func visible(b byte) string {
+	switch {
+	case b == 0x0A:
+		return `\n`
+	case b == 0x0D:
+		return `\r`
+	case b == 0x09:
+		return `\t`
+	case b < 0x20 || b > 0x7E:
+		return fmt.Sprintf(`\x%02x`, b)
+	}
+	return string(b)
+}
+
+// snippet shows the characters around a position of an input with a caret
+// under the one at the position, e.g. (indented by two spaces)
+//
+//	...8912345678912340567891234567891...
+//	                  ^
+//
+// Parameters:
+//   - p: The input
+//   - pos: The position, less than len(p)
+//
+// Returns:
+//   - string: Two indented lines, the characters and the caret
+func snippet(p []byte, pos int) string {
+	lo, hi := max(pos-snippetRadius, 0), min(pos+snippetRadius+1, len(p))
+	var text, caret strings.Builder
+	text.WriteString("  ")
+	caret.WriteString("  ")
+	if lo > 0 {
+		text.WriteString("...")
+		caret.WriteString("   ")
+	}
+	for i := lo; i < hi; i++ {
+		v := visible(p[i])
+		text.WriteString(v)
+		if i < pos {
+			caret.WriteString(strings.Repeat(" ", len(v)))
+		} else if i == pos {
+			caret.WriteString(strings.Repeat("^", len(v)))
+		}
+	}
+	if hi < len(p) {
+		text.WriteString("...")
+	}
+	return text.String() + "\n" + caret.String()
+}
+
+// withSnippet adds the line and column, if the input has several lines, and
+// a snippet of the input to the error of an invalid character.
+//
+// Parameters:
+//   - err: The error, wrapping a decodeways.SyntaxError at pos
+//   - p: The input
+//   - pos: The position of the invalid character
+//   - line: The line of the position, 0 for a single-line input
+//   - col: The column of the position, counted from 1
+//
+// Returns:
+//   - error: The error with the location, still wrapping err
+func withSnippet(err error, p []byte, pos, line, col int) error {
+	if line > 0 {
+		return fmt.Errorf("%w (line %d, col %d)\n%s", err, line, col, snippet(p, pos))
+	}
+	return fmt.Errorf("%w\n%s", err, snippet(p, pos))
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go driver, no cgo needed
+)
+
+// resultsSchema creates the table of the --sqlite results store.
+//
+// A row is one content of one file under one set of options: counting an
+// unchanged file again only updates its count and timestamp, while a file
+// whose content changed gets a new row, so the table keeps the history of
+// every dataset.
+const resultsSchema = `CREATE TABLE IF NOT EXISTS results (
+	file       TEXT NOT NULL, -- Absolute path of the input
+	hash       TEXT NOT NULL, -- SHA-256 of the input, hex
+	options    TEXT NOT NULL, -- Options affecting the count, e.g. the alphabet
+	count      TEXT NOT NULL, -- Number of decodings in decimal
+	counted_at TEXT NOT NULL, -- Time of the count, RFC 3339 in UTC
+	PRIMARY KEY (file, hash, options)
+)`
+
+// resultsDB is a SQLite database recording the counts of a batch.
+type resultsDB struct {
+	db     *sql.DB
+	upsert *sql.Stmt
+}
+
+// openResultsDB opens or creates a results database.
+//
+// Parameters:
+//   - path: Path of the database file
+//
+// Returns:
+//   - *resultsDB: The database
+//   - error: An error if the database can not be opened or created
+func openResultsDB(path string) (*resultsDB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(resultsSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	upsert, err := db.Prepare(`INSERT INTO results (file, hash, options, count, counted_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (file, hash, options) DO UPDATE SET count = excluded.count, counted_at = excluded.counted_at`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &resultsDB{db: db, upsert: upsert}, nil
+}
+
+// record upserts the count of a file.
+//
+// Parameters:
+//   - filename: Path of the input, stored as an absolute path
+//   - sum: SHA-256 of the input
+//   - options: The options of the count, see counter.options
+//   - count: The count in decimal
+//
+// Returns:
+//   - error: An error if the row can not be written
+func (r *resultsDB) record(filename string, sum []byte, options, count string) error {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	_, err = r.upsert.Exec(abs, hex.EncodeToString(sum), options, count, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("recording %s: %w", filename, err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (r *resultsDB) Close() error {
+	r.upsert.Close()
+	return r.db.Close()
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// logAddExp returns log(exp(a) + exp(b)) without overflowing.
+func logAddExp(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+// forwardBackward computes the number of decodings of every prefix and suffix.
+//
+// Counts grow exponentially, so they are kept as natural logarithms in
+// float64, which is plenty for probabilities: fw[i] is the log of the number
+// of decodings of p[:i] and bw[i] the log of the number of decodings of p[i:]
+// (-Inf if there are none). Every decoding passes through an edge i -> j of
+// the decoding DAG with probability exp(fw[i] + bw[j] - fw[n]).
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//
+// Returns:
+//   - fw, bw: Log-counts of prefix and suffix decodings, n+1 values each
+//   - error: An error if p contains a non-digit character or can not be decoded
+//
+// Time Complexity: O(n), Space Complexity: O(n)
+func forwardBackward(p []byte) (fw, bw []float64, err error) {
+	n := len(p)
+	if n == 0 {
+		return nil, nil, errors.New("empty string")
+	}
+	negInf := math.Inf(-1)
+	fw = make([]float64, n+1)
+	bw = make([]float64, n+1)
+	for i := 1; i <= n; i++ {
+		fw[i], bw[n-i] = negInf, negInf
+	}
+
+	err = dagEdges(p, func(from, to int, _ byte) error {
+		fw[to] = logAddExp(fw[to], fw[from])
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if math.IsInf(fw[n], -1) {
+		return nil, nil, errors.New("string can not be decoded")
+	}
+
+	// Edges come ordered by their start, so walk the positions backwards
+	for i := n - 1; i >= 0; i-- {
+		if p[i] != 0x30 {
+			bw[i] = bw[i+1]
+		}
+		if i+1 < n {
+			if v := int(p[i]-0x30)*10 + int(p[i+1]-0x30); v >= 10 && v <= 26 {
+				bw[i] = logAddExp(bw[i], bw[i+2])
+			}
+		}
+	}
+	return fw, bw, nil
+}
+
+// letterExpectations calculates the expected number of occurrences of every
+// letter in a uniformly random decoding of a digit string.
+//
+// By linearity of expectation it is the sum, over all DAG edges decoding to
+// the letter, of the probability that a random decoding uses that edge.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//
+// Returns:
+//   - [26]float64: Expected occurrences of the letters A-Z
+//   - error: An error if p is invalid or can not be decoded
+func letterExpectations(p []byte) ([26]float64, error) {
+	var e [26]float64
+	fw, bw, err := forwardBackward(p)
+	if err != nil {
+		return e, err
+	}
+	total := fw[len(p)]
+	dagEdges(p, func(from, to int, letter byte) error {
+		e[letter-'A'] += math.Exp(fw[from] + bw[to] - total)
+		return nil
+	})
+	return e, nil
+}
+
+// letterRecord is the record of a letter of --letter-stats.
+type letterRecord struct {
+	Letter   string  `json:"letter"`
+	Expected float64 `json:"expected"` // Expected occurrences in a random decoding
+}
+
+// plain implements plainRecord: "<letter>\t<expected>".
+func (r letterRecord) plain() string {
+	return fmt.Sprintf("%s\t%.6f\n", r.Letter, r.Expected)
+}
+
+// choicePoint is a position where a decoding can continue in two ways. It is
+// also the record of --entropy, whose last record, with Pos -1 and Reach 1,
+// holds the total entropy.
+type choicePoint struct {
+	Pos     int     `json:"pos"`     // Position in the digit string
+	Reach   float64 `json:"reach"`   // Probability that a random decoding has a letter boundary here
+	Entropy float64 `json:"entropy"` // Entropy in bits of the choice between a one- and a two-digit letter
+}
+
+// plain implements plainRecord: "<pos>\t<reach>\t<entropy>", or
+// "total\t<entropy>" for the total.
+func (c choicePoint) plain() string {
+	if c.Pos < 0 {
+		return fmt.Sprintf("total\t%.6f\n", c.Entropy)
+	}
+	return fmt.Sprintf("%d\t%.6f\t%.6f\n", c.Pos, c.Reach, c.Entropy)
+}
+
+// decodingEntropy breaks down the Shannon entropy of the uniform distribution
+// over decodings of a digit string by position.
+//
+// A random decoding is generated left to right: at a letter boundary i it
+// takes a one-digit letter with probability bw[i+1]/bw[i] and a two-digit one
+// with probability bw[i+2]/bw[i] (counts, not logs). By the chain rule the
+// total entropy, log2 of the number of decodings, is the sum over positions of
+// the probability of reaching the position times the entropy of that choice.
+// Only positions where both continuations are possible contribute.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//
+// Returns:
+//   - []choicePoint: The positions with a real choice
+//   - float64: The total entropy in bits
+//   - error: An error if p is invalid or can not be decoded
+func decodingEntropy(p []byte) ([]choicePoint, float64, error) {
+	fw, bw, err := forwardBackward(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	n := len(p)
+	var points []choicePoint
+	total := 0.0
+	for i := 0; i+1 < n; i++ {
+		if p[i] == 0x30 || math.IsInf(bw[i+1], -1) || math.IsInf(bw[i+2], -1) {
+			continue
+		}
+		if v := int(p[i]-0x30)*10 + int(p[i+1]-0x30); v < 10 || v > 26 {
+			continue
+		}
+		q1 := math.Exp(bw[i+1] - bw[i])
+		q2 := math.Exp(bw[i+2] - bw[i])
+		c := choicePoint{
+			Pos:     i,
+			Reach:   math.Exp(fw[i] + bw[i] - fw[n]),
+			Entropy: -(q1*math.Log2(q1) + q2*math.Log2(q2)),
+		}
+		points = append(points, c)
+		total += c.Reach * c.Entropy
+	}
+	return points, total, nil
+}
+
+// lengthRecord is the record of a length of --lengths.
+type lengthRecord struct {
+	Length int    `json:"length"` // Number of letters
+	Count  string `json:"count"`  // Number of decodings of that length in decimal
+}
+
+// plain implements plainRecord: "<length> <count>".
+func (r lengthRecord) plain() string {
+	return fmt.Sprintf("%d %s\n", r.Length, r.Count)
+}
+
+// lengthPoly is a polynomial with big.Int coefficients where coefficient k
+// stands for x^(lo+k); the offset keeps polynomials of long segments compact.
+type lengthPoly struct {
+	lo int
+	c  []*big.Int
+}
+
+// mul returns the product of two polynomials.
+func (a lengthPoly) mul(b lengthPoly) lengthPoly {
+	r := lengthPoly{lo: a.lo + b.lo, c: make([]*big.Int, len(a.c)+len(b.c)-1)}
+	for i := range r.c {
+		r.c[i] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i, x := range a.c {
+		if x.Sign() == 0 {
+			continue
+		}
+		for j, y := range b.c {
+			r.c[i+j].Add(r.c[i+j], t.Mul(x, y))
+		}
+	}
+	return r
+}
+
+// lengthDistribution calculates the number of decodings of every length.
+//
+// The result is the generating polynomial sum(count(k) * x^k), k being the
+// number of letters. The input is split into segments that no two-digit code
+// crosses; decodings of different segments combine freely, so the polynomial
+// is the product of the segment polynomials. Within a segment the classic DP
+// is run on polynomials, a letter multiplying by x:
+//
+//	P(i) = x * P(i-1) + x * P(i-2)
+//
+// with either term dropped when the corresponding code is invalid.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//
+// Returns:
+//   - lengthPoly: The generating polynomial
+//   - error: An error if p contains a non-digit character or can not be decoded
+//
+// Time Complexity: O(n^2) big.Int operations in the worst case (a single long segment)
+func lengthDistribution(p []byte) (lengthPoly, error) {
+	if len(p) == 0 {
+		return lengthPoly{}, errors.New("empty string")
+	}
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return lengthPoly{}, fmt.Errorf("encountered non-digit character at pos. %d", i)
+		}
+	}
+
+	total := lengthPoly{c: []*big.Int{big.NewInt(1)}}
+	for start := 0; start < len(p); {
+		// Find the end of the segment: the first pair that is not a valid code
+		end := start + 1
+		for end < len(p) {
+			if v := (p[end-1]-0x30)*10 + p[end] - 0x30; v < 10 || v > 26 {
+				break
+			}
+			end++
+		}
+		seg, err := segmentPoly(p[start:end])
+		if err != nil {
+			return lengthPoly{}, fmt.Errorf("%v at pos. %d", err, start)
+		}
+		total = total.mul(seg)
+		start = end
+	}
+	return total, nil
+}
+
+// segmentPoly runs the polynomial DP over a single segment.
+func segmentPoly(s []byte) (lengthPoly, error) {
+	// Polynomials of the prefixes i-2 and i-1; coefficient slices are shared
+	// but never modified in place
+	prev2, prev1 := lengthPoly{}, lengthPoly{c: []*big.Int{big.NewInt(1)}}
+	for i, b := range s {
+		var cur lengthPoly
+		if b != 0x30 {
+			cur = lengthPoly{lo: prev1.lo + 1, c: prev1.c}
+		}
+		if i > 0 && prev2.c != nil {
+			cur = addShifted(cur, prev2)
+		}
+		prev2, prev1 = prev1, cur
+	}
+	if prev1.c == nil {
+		return lengthPoly{}, errors.New("segment can not be decoded")
+	}
+	return prev1, nil
+}
+
+// addShifted returns a + x * b, b being non-empty.
+func addShifted(a, b lengthPoly) lengthPoly {
+	lo := b.lo + 1
+	if a.c == nil {
+		return lengthPoly{lo: lo, c: b.c}
+	}
+	if a.lo < lo {
+		lo = a.lo
+	}
+	hi := a.lo + len(a.c)
+	if h := b.lo + 1 + len(b.c); h > hi {
+		hi = h
+	}
+	r := lengthPoly{lo: lo, c: make([]*big.Int, hi-lo)}
+	for i := range r.c {
+		r.c[i] = new(big.Int)
+	}
+	for i, x := range a.c {
+		r.c[a.lo-lo+i].Add(r.c[a.lo-lo+i], x)
+	}
+	for i, x := range b.c {
+		r.c[b.lo+1-lo+i].Add(r.c[b.lo+1-lo+i], x)
+	}
+	return r
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sync/atomic"
+
+	"task1/decodeways"
+)
+
+// scanStatus is the state of the scans of the run, reported on demand by
+// signals (see watchStatusSignals). It is safe for concurrent use.
+type scanStatus struct {
+	total    atomic.Int64 // Bytes of the inputs being scanned
+	done     atomic.Int64 // Bytes scanned
+	clusters atomic.Int64 // Clusters found
+	bits     atomic.Int64 // Bit length of the product of their factors, in 1/1024 bits
+}
+
+// scans is the scan status of the run.
+var scans scanStatus
+
+// factorBits returns the number of clusters of a set of factors and the
+// binary logarithm of their product in 1/1024 bits, using
+// log2 F(n) ~ n*log2(phi) - log2(sqrt(5)).
+func factorBits(fc decodeways.Factors) (clusters, bits int64) {
+	phi := math.Log2((1 + math.Sqrt(5)) / 2)
+	for f, c := range fc {
+		clusters += int64(c)
+		bits += int64(c) * int64(1024*max(0, float64(f)*phi-math.Log2(math.Sqrt(5))))
+	}
+	return clusters, bits
+}
+
+// begin records the start of a scan of n bytes.
+func (st *scanStatus) begin(n int64) {
+	st.total.Add(n)
+}
+
+// add records n more bytes scanned, in which the clusters of fc were found.
+func (st *scanStatus) add(n int64, fc decodeways.Factors) {
+	clusters, bits := factorBits(fc)
+	st.done.Add(n)
+	st.clusters.Add(clusters)
+	st.bits.Add(bits)
+}
+
+// write writes the status as one line, e.g.
+//
+//	status: offset 402653184 of 1073741824 (37.5%), 98304 clusters, partial product ~1310720 bits
+//
+// Parameters:
+//   - w: The writer, usually stderr
+func (st *scanStatus) write(w io.Writer) {
+	total, done := st.total.Load(), st.done.Load()
+	if total == 0 {
+		fmt.Fprintln(w, "status: no scan started yet")
+		return
+	}
+	fmt.Fprintf(w, "status: offset %d of %d (%.1f%%), %d clusters, partial product ~%d bits\n",
+		done, total, 100*float64(done)/float64(total), st.clusters.Load(), st.bits.Load()/1024+1)
+}
+
+// watchStatusSignals reports the scan status on stderr on the signals of
+// statusSignals (SIGUSR1 where it exists), and on an interrupt (SIGINT)
+// before exiting with code 130, so a long run can be observed without
+// restarting it with extra flags.
+func watchStatusSignals() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, append(statusSignals, os.Interrupt)...)
+	go func() {
+		for s := range sig {
+			scans.write(os.Stderr)
+			if s == os.Interrupt {
+				exit(130)
+			}
+		}
+	}()
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build !unix
+
+package main
+
+import "os"
+
+// statusSignals are the signals that report the scan status. Without
+// SIGUSR1, the status is only reported on an interrupt.
+var statusSignals []os.Signal
@@ -0,0 +1,16 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// statusSignals are the signals that report the scan status.
+var statusSignals = []os.Signal{syscall.SIGUSR1}
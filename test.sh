@@ -8,7 +8,7 @@
 set -e  # Exit on error
 
 echo "Building decode-ways..."
-go build -o decode-ways main.go
+go build -o decode-ways .
 
 echo "Running on test2.txt..."
 ./decode-ways test2.txt
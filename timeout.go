@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// watchTimeout aborts the process once a wall-clock limit on the whole
+// computation (--timeout) is exceeded: the scan status is reported on stderr,
+// like on an interrupt, then the process exits with exitTimeout. The counts
+// can not be interrupted, so the limit is enforced from a goroutine waiting
+// on the deadline of a context, which calling the returned function cancels
+// once the computation is done in time.
+//
+// Parameters:
+//   - d: The time limit, 0 for none
+//
+// Returns:
+//   - context.CancelFunc: Cancels the limit
+func watchTimeout(d time.Duration) context.CancelFunc {
+	if d <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	go func() {
+		<-ctx.Done()
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		scans.write(os.Stderr)
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: not finished within the --timeout of %v\n", d)))
+		exit(exitTimeout)
+	}()
+	return cancel
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader serves a TLS certificate from files, picking up a rotated
+// certificate without a restart.
+//
+// The files are checked at most once per interval, when a handshake asks for
+// the certificate, and reloaded if either was modified. If the new pair can
+// not be loaded (e.g. the key was written but the certificate not yet), the
+// previous certificate stays in use and the next check tries again.
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration // Time between two checks, 0 to never reload
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time // Latest modification time of the two files
+	checked time.Time // Time of the last check
+}
+
+// newCertReloader loads a certificate and its key.
+//
+// Parameters:
+//   - certFile: Path of the PEM certificate (chain)
+//   - keyFile: Path of the PEM private key
+//   - interval: Time between two checks for a rotated certificate, 0 to never reload
+//
+// Returns:
+//   - *certReloader: The reloader
+//   - error: An error if the pair can not be loaded
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// modified returns the latest modification time of the certificate and key.
+func (r *certReloader) modified() (time.Time, error) {
+	var t time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return t, err
+		}
+		if fi.ModTime().After(t) {
+			t = fi.ModTime()
+		}
+	}
+	return t, nil
+}
+
+// load (re)loads the certificate and key. The caller must hold r.mu or own r.
+func (r *certReloader) load() error {
+	t, err := r.modified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert, r.modTime = &cert, t
+	return nil
+}
+
+// getCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.interval > 0 && time.Since(r.checked) >= r.interval {
+		r.checked = time.Now()
+		if t, err := r.modified(); err == nil && !t.Equal(r.modTime) {
+			if err := r.load(); err != nil {
+				slog.Error("reloading certificate failed", "err", err)
+			} else {
+				slog.Info("reloaded certificate", "file", r.certFile)
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// tlsConfig returns the server TLS configuration of the --tls-* flags.
+//
+// Parameters:
+//   - certFile: Path of the PEM certificate, empty to serve without TLS
+//   - keyFile: Path of the PEM private key
+//   - reload: Time between two checks for a rotated certificate, 0 to never reload
+//
+// Returns:
+//   - *tls.Config: The configuration, nil if TLS is disabled
+//   - error: An error if only one file is given or the pair can not be loaded
+func tlsConfig(certFile, keyFile string, reload time.Duration) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	r, err := newCertReloader(certFile, keyFile, reload)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+	return &tls.Config{GetCertificate: r.getCertificate, MinVersion: tls.VersionTLS12}, nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryDelay caps the delay between two attempts of a download.
+const maxRetryDelay = time.Minute
+
+// retryPolicy is how often and how patiently URL inputs are fetched
+// (--retries, --retry-backoff).
+type retryPolicy struct {
+	retries int           // Attempts after the first one
+	backoff time.Duration // Delay before the first retry, doubled for every other
+}
+
+// fetchPolicy is the retry policy of URL inputs, set from the flags.
+var fetchPolicy = retryPolicy{retries: 3, backoff: time.Second}
+
+// fetchClient fetches URL inputs. Its transport goes through the proxies of
+// $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY.
+var fetchClient = &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}
+
+// isURL tells whether an input argument is an HTTP(S) URL rather than a file.
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// retryableError is the error of an attempt that may succeed when repeated:
+// a network failure, a 5xx or a 429.
+type retryableError struct {
+	err   error
+	after time.Duration // Delay asked by Retry-After, 0 for none
+}
+
+// Error implements the error interface.
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// fetchURL downloads a URL input.
+//
+// Failed attempts are retried with exponential backoff, after the delay of a
+// Retry-After header if the server sends one. A download that breaks off is
+// resumed where it stopped with a Range request, guarded by If-Range so that
+// a file changed on the server is downloaded again from the start; a server
+// without range support sends it whole again.
+//
+// Parameters:
+//   - url: The URL
+//   - limit: Maximum length of the input in bytes (--max-len), 0 for none
+//
+// Returns:
+//   - []byte: The content
+//   - error: An error if the download failed after all retries, the server
+//     refused it, or the input is longer than limit
+func fetchURL(url string, limit int64) ([]byte, error) {
+	var (
+		p         []byte
+		validator string // ETag or Last-Modified of the partial content
+	)
+	delay := fetchPolicy.backoff
+	for attempt := 0; ; attempt++ {
+		var err error
+		p, validator, err = fetchAttempt(url, p, validator, limit)
+		if err == nil {
+			return p, nil
+		}
+		var re *retryableError
+		if !errors.As(err, &re) || attempt >= fetchPolicy.retries {
+			if re != nil {
+				err = re.err
+			}
+			return nil, &fs.PathError{Op: "fetching", Path: url, Err: err}
+		}
+		wait := max(delay, re.after)
+		slog.Warn("fetch failed, retrying", "url", url, "err", re.err, "bytes", len(p), "retry_in", wait)
+		time.Sleep(wait)
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// fetchAttempt makes one attempt of a download, continuing the content
+// received so far.
+//
+// Parameters:
+//   - url: The URL
+//   - p: The content received by earlier attempts
+//   - validator: The ETag or Last-Modified of p, "" for none
+//   - limit: Maximum length of the input in bytes, 0 for none
+//
+// Returns:
+//   - []byte: The content received so far, p extended or started over
+//   - string: The validator of the content
+//   - error: A retryableError if the attempt may be repeated
+func fetchAttempt(url string, p []byte, validator string, limit int64) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return p, validator, err
+	}
+	if len(p) > 0 && validator != "" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", len(p)))
+		req.Header.Set("If-Range", validator)
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return p, validator, &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		p = p[:0] // The whole content, from the start
+		if validator = resp.Header.Get("ETag"); validator == "" {
+			validator = resp.Header.Get("Last-Modified")
+		}
+		if resp.ContentLength >= 0 {
+			if err := checkInputLen(resp.ContentLength, limit); err != nil {
+				return p, validator, err
+			}
+		}
+	case resp.StatusCode == http.StatusPartialContent && len(p) > 0:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", len(p))) {
+			return p[:0], "", &retryableError{err: fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))}
+		}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return p, validator, &retryableError{err: errors.New(resp.Status), after: retryAfter(resp.Header.Get("Retry-After"))}
+	default:
+		return p, validator, errors.New(resp.Status)
+	}
+
+	r := io.Reader(resp.Body)
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1-int64(len(p)))
+	}
+	buf := bytes.NewBuffer(p) // Keeps what a broken read received
+	_, err = buf.ReadFrom(r)
+	p = buf.Bytes()
+	if limit > 0 && int64(len(p)) > limit {
+		return p, validator, &limitError{fmt.Sprintf("input is longer than the --max-len limit of %s", formatSize(limit))}
+	}
+	if err != nil {
+		return p, validator, &retryableError{err: err} // Resumed if there is a validator
+	}
+	return p, validator, nil
+}
+
+// retryAfter parses a Retry-After header in seconds or as an HTTP date.
+func retryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return min(time.Duration(n)*time.Second, maxRetryDelay)
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return min(max(time.Until(t), 0), maxRetryDelay)
+	}
+	return 0
+}
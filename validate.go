@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"task1/decodeways"
+)
+
+// errorList is the error of --all-errors: the invalid positions of an input,
+// up to --max-errors.
+type errorList struct {
+	errs []error
+	more bool // Whether there are more invalid positions than listed
+}
+
+// Error implements error, with one line per invalid position.
+func (l *errorList) Error() string {
+	var b strings.Builder
+	switch {
+	case l.more:
+		fmt.Fprintf(&b, "more than %d invalid positions, the first %d:", len(l.errs), len(l.errs))
+	case len(l.errs) == 1:
+		b.WriteString("1 invalid position:")
+	default:
+		fmt.Fprintf(&b, "%d invalid positions:", len(l.errs))
+	}
+	for _, err := range l.errs {
+		// Indent the snippet under its error
+		b.WriteString("\n  ")
+		b.WriteString(strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the positions, for errors.As.
+func (l *errorList) Unwrap() []error {
+	return l.errs
+}
+
+// collectErrors validates a whole input, reporting every invalid position
+// rather than only the first, so dirty data can be cleaned in one pass.
+//
+// The rules are those of the cluster scan: every character must be a digit,
+// and a digit that is not a code on its own must pair with the previous one.
+// After an invalid character the scan goes on with the next one, a
+// non-digit being a boundary after which digits start afresh. The first
+// position reported is the one the scan fails at.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - s: The letter mapping scheme
+//   - max: Maximum number of positions to report
+//
+// Returns:
+//   - *errorList: The invalid positions, nil if there are none
+func collectErrors(p []byte, s *decodeways.Scheme, max int) *errorList {
+	l := &errorList{}
+	lines := bytes.IndexByte(p, 0x0A) >= 0
+	line, lineStart := 1, 0 // Line of p[i] and offset of its first character
+	a := byte(0)            // Previous digit, 0 at a boundary
+	for i, b := range p {
+		var msg string
+		switch {
+		case b < 0x30 || b > 0x39: // Not '0'-'9'
+			msg = "encountered non-digit character"
+		case s.Single.Has(b - 0x30):
+		case i == 0:
+			msg = fmt.Sprintf("string starts with %c", b)
+		case a == 0:
+			msg = fmt.Sprintf("encountered %c after a non-digit character", b)
+		case !s.Pair[a-0x30].Has(b - 0x30):
+			msg = fmt.Sprintf("encountered %c which can not be attached to %c", b, a)
+		}
+		if msg != "" {
+			if len(l.errs) == max {
+				l.more = true
+				break
+			}
+			err := &decodeways.SyntaxError{Msg: msg, Pos: i}
+			if lines {
+				l.errs = append(l.errs, withSnippet(err, p, i, line, i-lineStart+1))
+			} else {
+				l.errs = append(l.errs, withSnippet(err, p, i, 0, i+1))
+			}
+		}
+
+		a = b
+		if b < 0x30 || b > 0x39 {
+			a = 0
+		}
+		if b == 0x0A {
+			line, lineStart = line+1, i+1
+		}
+	}
+	if len(l.errs) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"task1/decodeways"
+)
+
+// vectorShapes are the generator settings test vectors cycle through, from
+// plain digits to long clusters and frequent zeros, so a small set of
+// vectors covers all branches of an implementation.
+var vectorShapes = []decodeways.Generator{
+	{ZeroDensity: 0, MeanCluster: 0.5},
+	{ZeroDensity: 0.1, MeanCluster: 2},
+	{ZeroDensity: 0.3, MeanCluster: 2},
+	{ZeroDensity: 0.1, MeanCluster: 8},
+	{ZeroDensity: 0.5, MeanCluster: 8},
+}
+
+// writeVectors writes random valid inputs, one per line, with their counts
+// as a tab-separated second column if answers is set, for seeding the test
+// suites of other implementations. Every count is checked against an
+// independent oracle (see decodeways.CheckInvariant) before it is written.
+//
+// Parameters:
+//   - w: The writer
+//   - count: Number of vectors
+//   - maxLen: Maximum length of an input; lengths are uniform in 1..maxLen
+//   - seed: The seed of the random generator
+//   - answers: Whether to write the counts
+//
+// Returns:
+//   - error: An error if writing fails or a count fails its check
+func writeVectors(w io.Writer, count, maxLen int, seed int64, answers bool) error {
+	rnd := rand.New(rand.NewSource(seed))
+	bw := bufio.NewWriter(w)
+	if answers {
+		fmt.Fprintln(bw, "input\tcount")
+	}
+	for i := 0; i < count; i++ {
+		g := vectorShapes[i%len(vectorShapes)]
+		p := g.Valid(rnd, 1+rnd.Intn(maxLen))
+		if !answers {
+			fmt.Fprintf(bw, "%s\n", p)
+			continue
+		}
+		if err := decodeways.CheckInvariant(p, nil); err != nil {
+			return err
+		}
+		factors := decodeways.Factors{}
+		if err := decodeways.ScanClusters(p, 0, &decodeways.StandardScheme, factors); err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "%s\t%v\n", p, factors.Product())
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// verifyDecoding checks that a candidate decoding re-encodes exactly to the digit string.
+//
+// Every letter is encoded back to its code ('A' -> "1", ..., 'Z' -> "26") and
+// compared against the digits at the current position, so the first letter
+// that does not fit is reported together with the position where the two
+// strings diverge.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string
+//   - letters: Candidate decoding consisting of letters A-Z
+//
+// Returns:
+//   - error: nil if letters is a decoding of p, otherwise a description of the divergence
+func verifyDecoding(p []byte, letters string) error {
+	pos := 0
+	for i := 0; i < len(letters); i++ {
+		c := letters[i]
+		if c < 'A' || c > 'Z' {
+			return fmt.Errorf("letter %d: %q is not a letter A-Z", i, c)
+		}
+		code := strconv.Itoa(int(c-'A') + 1)
+		for j := 0; j < len(code); j++ {
+			if pos+j >= len(p) {
+				return fmt.Errorf("letter %d ('%c' = %s) runs past the end of the string at pos. %d", i, c, code, pos+j)
+			}
+			if p[pos+j] != code[j] {
+				return fmt.Errorf("letter %d ('%c' = %s) diverges at pos. %d: expected %q, found %q", i, c, code, pos+j, code[j], p[pos+j])
+			}
+		}
+		pos += len(code)
+	}
+	if pos != len(p) {
+		return fmt.Errorf("decoding ends at pos. %d, but the string has %d characters", pos, len(p))
+	}
+	return nil
+}
+
+// runVerify implements the "verify" subcommand.
+//
+// Usage: decode-ways verify <filename> <letters>
+//
+// Returns:
+//   - int: The process exit code
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	format := addFormatFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways verify <filename> <letters>")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways verify test.txt ABKF")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+
+	f, err := newFormatter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	p, err := readInput(fs.Arg(0), 0)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error %v\n", trError(err))))
+		return exitIO
+	}
+	r := verifyRecord{File: fs.Arg(0), Letters: fs.Arg(1), Match: true}
+	if err := verifyDecoding(p, fs.Arg(1)); err != nil {
+		fmt.Fprintf(os.Stderr, "Mismatch: %v\n", err)
+		r.Match, r.Error = false, err.Error()
+		if code := formatAll(f, r); code != 0 {
+			return code
+		}
+		return 1
+	}
+	return formatAll(f, r)
+}
+
+// verifyRecord is the record of verify.
+type verifyRecord struct {
+	File    string `json:"file"`
+	Letters string `json:"letters"`
+	Match   bool   `json:"match"`
+	Error   string `json:"error,omitempty"`
+}
+
+// plain implements plainRecord: "OK" if the letters are a decoding, the
+// mismatch being reported on stderr.
+func (r verifyRecord) plain() string {
+	if !r.Match {
+		return ""
+	}
+	return "OK\n"
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+//go:build js && wasm
+
+/*
+Command wasm exposes the decode-ways counter to JavaScript.
+
+Built for js/wasm, it registers a global function
+
+	countDecodeWays(digits: string): string
+
+returning the number of decodings in decimal (a string, as the count is
+usually far beyond Number.MAX_SAFE_INTEGER), or "Error decoding: ..." for
+invalid inputs. It only uses the decodeways library, so neither mmap nor any
+other part of the command line tool is compiled in.
+
+Build:
+
+	GOOS=js GOARCH=wasm go build -o decode-ways.wasm ./wasm
+
+and load it with the wasm_exec.js of the same Go version.
+*/
+package main
+
+import (
+	"syscall/js"
+
+	"task1/decodeways"
+)
+
+// countDecodeWays implements the JavaScript function countDecodeWays.
+//
+// Parameters:
+//   - args: The digit string as the only argument
+//
+// Returns:
+//   - any: The count in decimal, or the error message
+func countDecodeWays(_ js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return "Error: countDecodeWays expects one string argument"
+	}
+	factors := decodeways.Factors{}
+	if err := decodeways.ScanClusters([]byte(args[0].String()), 0, &decodeways.StandardScheme, factors); err != nil {
+		return "Error decoding: " + err.Error()
+	}
+	return factors.Product().String()
+}
+
+func main() {
+	js.Global().Set("countDecodeWays", js.FuncOf(countDecodeWays))
+	select {} // Keep the function callable
+}
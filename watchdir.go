@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// fileStamp is what a poll saw of a file: a file is only picked up once it
+// looks the same in two polls in a row, so files still being written are
+// left alone.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// processDropped counts a file found in the watched directory, writes the
+// result next to it as a sidecar file, and moves both to the done directory.
+//
+// The sidecar is "<name>.count" with the count, or "<name>.error" with the
+// error if the file is invalid; either way the input is moved out of the
+// watched directory so that it is not picked up again.
+//
+// Parameters:
+//   - c: The counter
+//   - path: Path of the input in the watched directory
+//   - done: The directory processed inputs are moved to
+//
+// Returns:
+//   - error: An error if the result can not be written or the file not moved
+func processDropped(c *counter, path, done string) error {
+	name := filepath.Base(path)
+	sidecar, data := name+".count", ""
+	x, err := countFile(c, path, nil)
+	if err != nil {
+		sidecar, data = name+".error", fmt.Sprintf("Error decoding: %v\n", err)
+		slog.Warn("invalid input", "file", name, "err", err)
+	} else {
+		data = x + "\n"
+		fmt.Printf("%s: %s\n", name, x)
+	}
+	if err := writeFileAtomic(filepath.Join(done, sidecar), []byte(data)); err != nil {
+		return err
+	}
+	return os.Rename(path, filepath.Join(done, name))
+}
+
+// runWatchdir implements the "watchdir" subcommand: a drop-folder service
+// that counts every file put into a directory.
+//
+// The directory is polled every --interval. Files that stopped changing are
+// counted in the order of their names, each followed by a line
+// "<name>: <count>" on stdout, and moved to the --done directory along with
+// a sidecar file holding the result (see processDropped). Hidden files are
+// ignored. The service runs until it gets SIGINT or SIGTERM.
+//
+// Usage: decode-ways watchdir <dir> --done <dir> [--interval 1s]
+//
+// Returns:
+//   - int: The process exit code
+func runWatchdir(args []string) int {
+	fs := flag.NewFlagSet("watchdir", flag.ExitOnError)
+	done := fs.String("done", "", "`dir` the processed inputs and their results are moved to")
+	interval := fs.Duration("interval", time.Second, "time between two scans of the directory")
+	countOpts := addCountFlags(fs)
+	logOpts := addLogFlags(fs, "info")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: decode-ways watchdir <dir> --done <dir> [--interval 1s]")
+		fmt.Fprintln(os.Stderr, "Example: decode-ways watchdir ./incoming --done ./processed")
+		fs.PrintDefaults()
+	}
+	// The directory may come before or after the flags
+	parseFlags(fs, args)
+	dir := fs.Arg(0)
+	if fs.NArg() > 0 {
+		fs.Parse(fs.Args()[1:])
+	}
+	if dir == "" || *done == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+	if err := setupLogging(*logOpts); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+
+	c, err := newCounter(*countOpts)
+	if err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	if err := os.MkdirAll(*done, 0o755); err != nil {
+		fmt.Fprint(os.Stderr, diagnostic(tr("Error: %v\n", trError(err))))
+		return 1
+	}
+	showProgress = false
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	tick := time.NewTicker(*interval)
+	defer tick.Stop()
+	seen := map[string]fileStamp{} // Files of the previous poll
+	for {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			slog.Error("reading directory failed", "dir", dir, "err", err)
+			return 1
+		}
+		next := map[string]fileStamp{}
+		for _, e := range entries {
+			if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			fi, err := e.Info()
+			if err != nil {
+				continue // Removed since ReadDir
+			}
+			st := fileStamp{fi.Size(), fi.ModTime()}
+			if prev, ok := seen[e.Name()]; !ok || prev != st {
+				next[e.Name()] = st // New or still changing, look again next time
+				continue
+			}
+			if err := processDropped(c, filepath.Join(dir, e.Name()), *done); err != nil {
+				slog.Error("processing failed", "file", e.Name(), "err", err)
+				return 1
+			}
+		}
+		seen = next
+
+		select {
+		case <-sig:
+			return 0
+		case <-tick.C:
+		}
+	}
+}
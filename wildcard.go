@@ -0,0 +1,223 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"task1/decodeways"
+)
+
+// digitSet is the set of digits allowed at a position of a pattern, bit d
+// standing for digit d.
+type digitSet decodeways.DigitSet
+
+// anyDigit is the set matched by the '*' wildcard: any digit 1-9.
+const anyDigit digitSet = 0x3FE
+
+// singles returns the number of digits in s that are valid one-digit codes
+// of the scheme sc.
+func (s digitSet) singles(sc *decodeways.Scheme) int64 {
+	return int64(bits.OnesCount16(uint16(s & digitSet(sc.Single))))
+}
+
+// pairs returns the number of valid two-digit codes "ab" of the scheme sc
+// with a in s and b in t.
+func (s digitSet) pairs(t digitSet, sc *decodeways.Scheme) int64 {
+	var n int64
+	for a := 0; a < 10; a++ {
+		if s&(1<<a) != 0 {
+			n += int64(bits.OnesCount16(uint16(t & digitSet(sc.Pair[a]))))
+		}
+	}
+	return n
+}
+
+// hasWildcards reports whether the input uses the wildcard syntax.
+func hasWildcards(p []byte) bool {
+	return bytes.IndexAny(p, "*[") >= 0
+}
+
+// parsePattern converts a digit string with wildcards into per-position digit sets.
+//
+// Besides plain digits, a position can be:
+//   - '*': any digit 1-9
+//   - a bracket class such as "[1-5]" or "[37]": any of the listed digits or ranges
+//
+// Parameters:
+//   - p: Byte slice containing digits, wildcards and bracket classes
+//
+// Returns:
+//   - []digitSet: The set of digits allowed at every position
+//   - error: An error if p contains any other character or a malformed class
+func parsePattern(p []byte) ([]digitSet, error) {
+	sets := make([]digitSet, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		b := p[i]
+		switch {
+		case b == '*':
+			sets = append(sets, anyDigit)
+		case b >= 0x30 && b <= 0x39:
+			sets = append(sets, 1<<(b-0x30))
+		case b == '[':
+			s, n, err := parseClass(p[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%v in class at pos. %d", err, i)
+			}
+			sets = append(sets, s)
+			i += n - 1
+		default:
+			return nil, fmt.Errorf("encountered invalid character %q at pos. %d", b, i)
+		}
+	}
+	return sets, nil
+}
+
+// parseClass parses a bracket class like "[1-5]" or "[37]" at the start of p.
+//
+// Returns:
+//   - digitSet: The digits listed in the class
+//   - int: The length of the class including the brackets
+//   - error: An error if the class is malformed or empty
+func parseClass(p []byte) (digitSet, int, error) {
+	var s digitSet
+	for i := 1; i < len(p); i++ {
+		b := p[i]
+		switch {
+		case b == ']':
+			if s == 0 {
+				return 0, 0, errors.New("empty digit set")
+			}
+			return s, i + 1, nil
+		case b < 0x30 || b > 0x39:
+			return 0, 0, fmt.Errorf("invalid character %q", b)
+		case i+2 < len(p) && p[i+1] == '-':
+			e := p[i+2]
+			if e < b || e > 0x39 {
+				return 0, 0, fmt.Errorf("invalid range %c-%c", b, e)
+			}
+			for d := b; d <= e; d++ {
+				s |= 1 << (d - 0x30)
+			}
+			i += 2
+		default:
+			s |= 1 << (b - 0x30)
+		}
+	}
+	return 0, 0, errors.New("missing ']'")
+}
+
+// countWildcard calculates the number of ways to decode a digit string with
+// wildcards, summed over every concrete digit string consistent with it.
+//
+// This is the "Decode Ways II" variant where '*' stands for any digit 1-9,
+// generalized to bracket classes which allow an arbitrary set of digits at a
+// position (see parsePattern). Wildcards break the cluster trick, since a
+// position contributes a varying number of one- and two-digit codes, so the
+// classic DP is used instead:
+//
+//	ways(i) = ways(i-1) * singles(i) + ways(i-2) * pairs(i-1, i)
+//
+// where singles and pairs count the valid codes of the scheme at a position
+// given the allowed digit sets. '*' stays any digit 1-9 whatever the scheme.
+//
+// Parameters:
+//   - p: Byte slice containing digits, wildcards and bracket classes
+//   - sc: The letter mapping scheme
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if the input is invalid or can not be decoded at all
+//
+// Time Complexity: O(n) big.Int operations
+func countWildcard(p []byte, sc *decodeways.Scheme) (*big.Int, error) {
+	sets, err := parsePattern(p)
+	if err != nil {
+		return big.NewInt(0), err
+	}
+	if len(sets) == 0 {
+		return big.NewInt(0), errors.New("empty string")
+	}
+
+	prev2, prev1 := big.NewInt(0), big.NewInt(1) // ways(i-2), ways(i-1)
+	cur, t, c := new(big.Int), new(big.Int), new(big.Int)
+	for i, s := range sets {
+		cur.Mul(prev1, c.SetInt64(s.singles(sc)))
+		if i > 0 {
+			if n := sets[i-1].pairs(s, sc); n > 0 {
+				cur.Add(cur, t.Mul(prev2, c.SetInt64(n)))
+			}
+		}
+		prev2, prev1, cur = prev1, cur, prev2
+	}
+	if prev1.Sign() == 0 {
+		return prev1, errors.New("no digit assignment can be decoded")
+	}
+	return prev1, nil
+}
+
+// countWildcardMod calculates the number of ways to decode a digit string
+// with wildcards modulo m, the answer LeetCode 639 asks for with m = 10^9+7.
+// It runs the DP of countWildcard in machine words, like countMod does for
+// plain digit strings.
+//
+// Parameters:
+//   - p: Byte slice containing digits, wildcards and bracket classes
+//   - sc: The letter mapping scheme
+//   - m: The modulus, 0 < m < 2^63
+//
+// Returns:
+//   - uint64: The number of possible decodings modulo m
+//   - error: An error if the input is invalid or can not be decoded at all
+//
+// Time Complexity: O(n), Space Complexity: O(n) for the digit sets
+func countWildcardMod(p []byte, sc *decodeways.Scheme, m uint64) (uint64, error) {
+	if m == 0 || m >= maxModulus {
+		return 0, fmt.Errorf("modulus %d out of range", m)
+	}
+	sets, err := parsePattern(p)
+	if err != nil {
+		return 0, err
+	}
+	if len(sets) == 0 {
+		return 0, errors.New("empty string")
+	}
+
+	prev2, prev1 := uint64(0), 1%m // ways(i-2), ways(i-1)
+	// Whether ways(i-2) and ways(i-1) are non-zero, which their residues do
+	// not tell
+	reach2, reach1 := false, true
+	for i, s := range sets {
+		cur := mulMod(prev1, uint64(s.singles(sc)), m)
+		reach := reach1 && s.singles(sc) > 0
+		if i > 0 {
+			if n := sets[i-1].pairs(s, sc); n > 0 {
+				cur += mulMod(prev2, uint64(n), m) // Both residues are below 2^63
+				if cur >= m {
+					cur -= m
+				}
+				reach = reach || reach2
+			}
+		}
+		prev2, prev1 = prev1, cur
+		reach2, reach1 = reach1, reach
+	}
+	if !reach1 {
+		return 0, errors.New("no digit assignment can be decoded")
+	}
+	return prev1, nil
+}
+
+// mulMod returns a*b mod m without overflowing.
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, r := bits.Div64(hi%m, lo, m)
+	return r
+}
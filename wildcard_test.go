@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"task1/decodeways"
+)
+
+// leetcodeMod is the modulus of the answers of LeetCode 639.
+const leetcodeMod = 1_000_000_007
+
+func TestCountWildcard(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"*", "9"},
+		{"1*", "18"},
+		{"2*", "15"},
+		{"*0", "2"},  // "10", "20"
+		{"**", "96"}, // 81 pairs of singles and the 15 codes 11-19, 21-26
+		{"*1", "11"}, // 9 singles, "11" and "21"
+		{"*7", "10"}, // 9 singles and "17"
+		{"3*", "9"},
+		{"0*", ""},
+		{"*00", ""},
+		{"226", "3"}, // Plain digits count as usual
+		{"[12]6", "4"},
+		{"[1-3]0", "2"},
+		{"[37]", "2"},
+		{"1[", ""},
+		{"1[]", ""},
+		{"[5-1]", ""},
+		{"1x", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := countWildcard([]byte(tt.in), &decodeways.StandardScheme)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("countWildcard(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("countWildcard(%q) = %v, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestCountWildcardMod(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"*", 9},
+		{"1*", 18},
+		{"2*", 15},
+		{"*0", 2},
+		{"**", 96},
+		{"**********1111111111", 133236775},
+	}
+	for _, tt := range tests {
+		got, err := countWildcardMod([]byte(tt.in), &decodeways.StandardScheme, leetcodeMod)
+		if err != nil || got != tt.want {
+			t.Errorf("countWildcardMod(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	// The residues of long inputs agree with the exact count
+	for _, in := range []string{strings.Repeat("*", 200), strings.Repeat("1*2[0-6]", 50)} {
+		want, err := countWildcard([]byte(in), &decodeways.StandardScheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.Mod(want, big.NewInt(leetcodeMod))
+		got, err := countWildcardMod([]byte(in), &decodeways.StandardScheme, leetcodeMod)
+		if err != nil || got != want.Uint64() {
+			t.Errorf("countWildcardMod(%.20q...) = %d, %v, want %v", in, got, err, want)
+		}
+	}
+
+	// A count that is a multiple of the modulus is still decodable
+	if got, err := countWildcardMod([]byte("**"), &decodeways.StandardScheme, 96); err != nil || got != 0 {
+		t.Errorf("countWildcardMod(\"**\", 96) = %d, %v, want 0", got, err)
+	}
+	for _, in := range []string{"*00", "0*", "1x"} {
+		if got, err := countWildcardMod([]byte(in), &decodeways.StandardScheme, leetcodeMod); err == nil {
+			t.Errorf("countWildcardMod(%q) = %d, want an error", in, got)
+		}
+	}
+}
+
+func TestCheckWildcard(t *testing.T) {
+	for _, in := range []string{"*", "1*", "*0", "**", "[12]6"} {
+		if err := checkWildcard([]byte(in), &decodeways.StandardScheme); err != nil {
+			t.Errorf("checkWildcard(%q) = %v, want nil", in, err)
+		}
+	}
+	for _, in := range []string{"0*", "*00", "[3-9]0", "1[", ""} {
+		if err := checkWildcard([]byte(in), &decodeways.StandardScheme); err == nil {
+			t.Errorf("checkWildcard(%q) = nil, want an error", in)
+		}
+	}
+}
+
+func TestCountWildcardScheme(t *testing.T) {
+	zeroBased, err := newCodeRange(26, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := newCodeRange(12, false) // 1-12
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		r    codeRange
+		in   string
+		want string
+	}{
+		{zeroBased, "0[12]", "2"}, // "01", "02": a leading zero is no pair
+		{zeroBased, "2*", "14"},   // 9 pairs of singles and 21-25
+		{zeroBased, "*0", "11"},   // 9 pairs of singles and 10, 20
+		{zeroBased, "0", "1"},
+		{small, "1*", "11"}, // 9 pairs of singles and 11, 12
+		{small, "*0", "1"},  // "10"
+		{small, "2*", "9"},
+		{small, "[12]3", "2"},
+	}
+	for _, tt := range tests {
+		sc := tt.r.scheme()
+		got, err := countWildcard([]byte(tt.in), &sc)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("countWildcard(%q, %v) = %v, %v, want %s", tt.in, tt.r, got, err, tt.want)
+		}
+		m, err := countWildcardMod([]byte(tt.in), &sc, leetcodeMod)
+		if err != nil || big.NewInt(int64(m)).String() != tt.want {
+			t.Errorf("countWildcardMod(%q, %v) = %d, %v, want %s", tt.in, tt.r, m, err, tt.want)
+		}
+		if err := checkWildcard([]byte(tt.in), &sc); err != nil {
+			t.Errorf("checkWildcard(%q, %v) = %v, want nil", tt.in, tt.r, err)
+		}
+	}
+	sc := small.scheme()
+	if err := checkWildcard([]byte("*00"), &sc); err == nil {
+		t.Errorf("checkWildcard(\"*00\", %v) = nil, want an error", small)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+	"math/big"
+
+	"task1/decodeways"
+)
+
+// codeSet is a set of digit codes of variable length, at most width() digits.
+type codeSet interface {
+	valid(c []byte) bool
+	width() int
+}
+
+// valid reports whether a digit string is a code of the range.
+func (r codeRange) valid(c []byte) bool {
+	if len(c) > 1 && c[0] == 0x30 {
+		return false // Leading zero
+	}
+	v := 0
+	for _, b := range c {
+		v = v*10 + int(b-0x30)
+	}
+	return v >= r.lo && v <= r.hi
+}
+
+// countWindow calculates the number of ways to decode a digit string whose
+// codes can be longer than two digits, or more generally the number of ways
+// to tokenize it into codes of an arbitrary code set.
+//
+// With codes of up to m digits the pairwise cluster logic no longer applies,
+// so the classic DP is generalized to an m-wide window:
+//
+//	ways(i) = sum over l = 1..m of ways(i-l) * [p[i-l:i] is a code]
+//
+// To keep the numbers in the window small, the input is cut wherever no
+// multi-digit code crosses the boundary: the decodings on both sides are
+// then independent, so the count so far is multiplied into the result and
+// the window restarts from 1.
+//
+// Parameters:
+//   - p: Byte slice containing the digit string to decode
+//   - r: The code set, e.g. the code range of the alphabet
+//
+// Returns:
+//   - *big.Int: The number of possible decodings
+//   - error: An error if the input is invalid
+//
+// Time Complexity: O(n * m^2) digit checks plus O(n * m) big.Int additions
+func countWindow(p []byte, r codeSet) (*big.Int, error) {
+	if len(p) == 0 {
+		return big.NewInt(0), errors.New("empty string")
+	}
+	m := r.width()
+	x := big.NewInt(1) // Product over the finished segments
+
+	// w[j] holds the number of decodings of the segment up to j digits back,
+	// w[0] being the current position
+	w := make([]*big.Int, m+1)
+	for j := range w {
+		w[j] = new(big.Int)
+	}
+	w[0].SetInt64(1)
+	seg := 0 // Start of the current segment
+
+	for i, b := range p {
+		if b < 0x30 || b > 0x39 { // Not '0'-'9'
+			return big.NewInt(0), &decodeways.SyntaxError{Msg: "encountered non-digit character", Pos: i}
+		}
+
+		// Shift the window and sum the codes ending at i
+		last := w[m]
+		copy(w[1:], w[:m])
+		w[0] = last.SetInt64(0)
+		for l := 1; l <= m && i+1-l >= seg; l++ {
+			if w[l].Sign() != 0 && r.valid(p[i+1-l:i+1]) {
+				w[0].Add(w[0], w[l])
+			}
+		}
+
+		// Cut after i if no multi-digit code crosses the boundary
+		if !crosses(r, p, i+1, m) {
+			if w[0].Sign() == 0 {
+				return big.NewInt(0), &decodeways.SyntaxError{Msg: "string can not be decoded", Pos: i}
+			}
+			x.Mul(x, w[0])
+			for j := range w {
+				w[j].SetInt64(0)
+			}
+			w[0].SetInt64(1)
+			seg = i + 1
+		}
+	}
+	return x, nil
+}
+
+// crosses reports whether a code of 2..m digits in p spans the boundary
+// before position b.
+func crosses(r codeSet, p []byte, b, m int) bool {
+	for s := b - 1; s >= 0 && s > b-m; s-- {
+		for e := b + 1; e <= len(p) && e-s <= m; e++ {
+			if r.valid(p[s:e]) {
+				return true
+			}
+		}
+	}
+	return false
+}
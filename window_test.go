@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"task1/decodeways"
+)
+
+// tokenizations counts the tokenizations of p into codes of r with the plain
+// DP, without the cuts of countWindow.
+func tokenizations(p []byte, r codeSet) *big.Int {
+	ways := make([]*big.Int, len(p)+1)
+	ways[0] = big.NewInt(1)
+	for i := 1; i <= len(p); i++ {
+		ways[i] = new(big.Int)
+		for l := 1; l <= r.width() && l <= i; l++ {
+			if r.valid(p[i-l : i]) {
+				ways[i].Add(ways[i], ways[i-l])
+			}
+		}
+	}
+	return ways[len(p)]
+}
+
+func TestCountWindow(t *testing.T) {
+	tests := []struct {
+		k         int
+		zeroBased bool
+		in        string
+		want      string
+	}{
+		{33, false, "3030", "1"},  // 30|30
+		{33, false, "3333", "5"},  // F(5), as for "1111"
+		{999, false, "999", "4"},  // 9|9|9, 99|9, 9|99, 999
+		{999, false, "100", "1"},  // 100 only
+		{999, false, "1000", "0"}, // The last '0' can not be attached
+		{100, true, "99", "2"},    // 0-99: 9|9, 99
+		{100, true, "00", "1"},    // 0|0, no leading zero
+		{26, false, "226", "3"},
+	}
+	for _, tt := range tests {
+		r, err := newCodeRange(tt.k, tt.zeroBased)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := countWindow([]byte(tt.in), r)
+		if tt.want == "0" {
+			if err == nil {
+				t.Errorf("countWindow(%q, %v) = %v, want an error", tt.in, r, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("countWindow(%q, %v) = %v, %v, want %s", tt.in, r, got, err, tt.want)
+		}
+	}
+	if _, err := countWindow([]byte("12a"), codeRange{1, 999}); err == nil {
+		t.Error("countWindow accepts a non-digit character")
+	}
+}
+
+// TestCountWindowRandom checks the cuts of countWindow against the plain DP,
+// and the window of the standard alphabet against the cluster scan.
+func TestCountWindowRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ranges := []codeRange{{1, 26}, {0, 25}, {1, 33}, {1, 999}, {0, 4999}, {1, 120}}
+	for n := 0; n < 2000; n++ {
+		r := ranges[n%len(ranges)]
+		p := make([]byte, 1+rng.Intn(40))
+		for i := range p {
+			p[i] = byte(0x30 + rng.Intn(10))
+			if rng.Intn(3) > 0 {
+				p[i] = byte(0x31 + rng.Intn(3)) // Mostly small digits, so codes chain
+			}
+		}
+		want := tokenizations(p, r)
+		got, err := countWindow(p, r)
+		if want.Sign() == 0 {
+			if err == nil {
+				t.Errorf("countWindow(%q, %v) = %v, want an error", p, r, got)
+			}
+		} else if err != nil || got.Cmp(want) != 0 {
+			t.Errorf("countWindow(%q, %v) = %v, %v, want %v", p, r, got, err, want)
+		}
+		if err := checkWindow(p, r); (err == nil) != (want.Sign() != 0) {
+			t.Errorf("checkWindow(%q, %v) = %v, but the count is %v", p, r, err, want)
+		}
+		if r.width() <= 2 {
+			sc := r.scheme()
+			x, err := getPossibleCombinations(p, &sc)
+			if want.Sign() != 0 && (err != nil || x.Cmp(want) != 0) {
+				t.Errorf("the cluster scan counts %v, %v decodings of %q under %v, want %v", x, err, p, r, want)
+			}
+		}
+	}
+}
+
+func TestCodeRangeScheme(t *testing.T) {
+	r, err := newCodeRange(26, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.width() != 2 || r.scheme() != decodeways.StandardScheme {
+		t.Errorf("the range of 26 letters has width %d and scheme %+v, want the standard scheme", r.width(), r.scheme())
+	}
+	for _, tt := range []struct {
+		k     int
+		width int
+	}{{9, 1}, {10, 2}, {99, 2}, {100, 3}, {999, 3}, {1000, 4}} {
+		if r, _ := newCodeRange(tt.k, false); r.width() != tt.width {
+			t.Errorf("newCodeRange(%d).width() = %d, want %d", tt.k, r.width(), tt.width)
+		}
+	}
+	for _, k := range []int{0, -1, maxCode + 1} {
+		if _, err := newCodeRange(k, false); err == nil {
+			t.Errorf("newCodeRange(%d) accepts the size", k)
+		}
+	}
+}
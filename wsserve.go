@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"task1/decodeways"
+)
+
+// upgrader upgrades /ws requests to WebSocket connections.
+var upgrader = websocket.Upgrader{}
+
+// formatRunning formats a running count under the options of the counter.
+func (c *counter) formatRunning(x *big.Int) string {
+	if c.mod != 0 {
+		return x.Mod(x, new(big.Int).SetUint64(c.mod)).String()
+	}
+	if c.atLeast != nil && x.Cmp(c.atLeast) >= 0 {
+		return "≥ " + c.atLeast.String()
+	}
+	return x.String()
+}
+
+// handleWS implements GET /ws: every message the client sends is appended to
+// its input, and is answered with the count of the input so far, or with the
+// error if the fragment is invalid or would take the input over --max-input,
+// in which case it is dropped and the client can go on with a corrected one.
+// Each connection has its own input, counted incrementally by a
+// decodeways.Builder. With quotas, every fragment is charged to the API key
+// of the connection, and once the key is over its quota the fragments are
+// refused like invalid ones.
+func (sv *server) handleWS(w http.ResponseWriter, r *http.Request) {
+	if sv.c.wide {
+		writeError(w, http.StatusBadRequest, "incremental counting only supports codes of up to two digits")
+		return
+	}
+	if sv.c.lenient || sv.c.zeros != zerosError {
+		writeError(w, http.StatusBadRequest, "incremental counting does not support --lenient or --zeros")
+		return
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader has replied
+	}
+	defer conn.Close()
+	if sv.maxInput > 0 {
+		conn.SetReadLimit(sv.maxInput) // A larger message closes the connection
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-sv.closing:
+			// Hijacked connections are not drained by http.Server.Shutdown
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	b := decodeways.NewBuilder(sv.c.s)
+	tenant, _ := tenantOf(r.Context())
+	for {
+		_, p, err := conn.ReadMessage()
+		if err != nil {
+			return // Closed by the client
+		}
+		reply := ""
+		if err := sv.quotas.check(tenant); err != nil {
+			reply = "Error: " + err.Error()
+		} else if sv.maxInput > 0 && b.Len()+int64(len(p)) > sv.maxInput {
+			reply = fmt.Sprintf("Error: input larger than %d bytes", sv.maxInput)
+		} else {
+			reply = sv.countFragment(r.Context(), b, p)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// countFragment appends a fragment to the input of a WebSocket connection
+// and counts the input so far, holding a worker of the compute pool, as both
+// multiply big.Ints. The count is charged to the tenant of the connection.
+//
+// Parameters:
+//   - ctx: The context of the connection
+//   - b: The builder of the input
+//   - p: The fragment
+//
+// Returns:
+//   - string: The reply, the count so far or the error of the fragment, which
+//     is dropped if no compute worker is free
+func (sv *server) countFragment(ctx context.Context, b *decodeways.Builder, p []byte) string {
+	ctx, cancel := sv.withTimeout(ctx)
+	defer cancel()
+	release, err := sv.compute.acquire(ctx, true)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Sprintf("Error: count not finished within %v", sv.timeout)
+	} else if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	defer release()
+	start := time.Now()
+	defer func() { sv.chargeCount(ctx, int64(len(p)), time.Since(start)) }()
+	if err := b.Append(p); err != nil {
+		return fmt.Sprintf("Error decoding: %v", err)
+	}
+	x, err := b.Count()
+	if err != nil {
+		return fmt.Sprintf("Error decoding: %v", err)
+	}
+	return sv.c.formatRunning(x)
+}
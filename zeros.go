@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Serhii Nesterenko
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"errors"
+
+	"task1/decodeways"
+)
+
+// Policies of --zeros for a '0' (generally, a digit that is not a code on its
+// own) that can not be attached to the previous digit.
+const (
+	zerosError = "error" // The input is invalid (the default)
+	zerosZero  = "zero"  // The input has no decoding, count 0 (LeetCode)
+	zerosSplit = "split" // The zero is a boundary between independent segments
+)
+
+// zeroPolicies is the set of valid --zeros policies.
+var zeroPolicies = map[string]bool{zerosError: true, zerosZero: true, zerosSplit: true}
+
+// digitError reports whether the error of a count is due to a digit, rather
+// than a non-digit character, so --zeros zero can make the count 0.
+func digitError(err error, p []byte) bool {
+	var se *decodeways.SyntaxError
+	return errors.As(err, &se) && se.Pos < len(p) && p[se.Pos] >= 0x30 && p[se.Pos] <= 0x39
+}
+
+// splitStandalone splits runs of an input at the standalone zeros of
+// --zeros split: digits that are not a code on their own and do not pair
+// with the previous digit, e.g. the '0' of "30" or the second one of "100".
+// They are dropped, and the digits on either side counted separately.
+//
+// Parameters:
+//   - p: The input
+//   - runs: The start and end offsets of the runs
+//   - s: The letter mapping scheme
+//
+// Returns:
+//   - [][2]int: The non-empty runs between the standalone zeros
+func splitStandalone(p []byte, runs [][2]int, s *decodeways.Scheme) [][2]int {
+	var out [][2]int
+	for _, r := range runs {
+		start := r[0]
+		for i := r[0]; i < r[1]; i++ {
+			b := p[i]
+			if b < 0x30 || b > 0x39 || s.Single.Has(b-0x30) {
+				continue
+			}
+			if i > start && p[i-1] >= 0x30 && p[i-1] <= 0x39 && s.Pair[p[i-1]-0x30].Has(b-0x30) {
+				continue
+			}
+			if i > start {
+				out = append(out, [2]int{start, i})
+			}
+			start = i + 1
+		}
+		if r[1] > start {
+			out = append(out, [2]int{start, r[1]})
+		}
+	}
+	return out
+}